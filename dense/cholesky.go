@@ -7,6 +7,9 @@ package dense
 
 import (
 	"math"
+	"sync"
+
+	"github.com/gonum/blas"
 )
 
 // CholFactors contains the Cholesky factors of a symmetric, positive
@@ -18,10 +21,12 @@ type CholFactors struct {
 }
 
 // Chol returns the Cholesky decomposition of the matrix M.
-func Chol(M *Dense) (*CholFactors, bool) {
+// M is only read, so it may be any Matrix, e.g. a Transpose view
+// or a *SymDense.
+func Chol(M Matrix) (*CholFactors, bool) {
 	ch := &CholFactors{nil}
-	n := M.Rows()
-	if M.Cols() != n {
+	n, c := M.Dims()
+	if c != n {
 		return ch, false
 	}
 	b := ch.Chol(M)
@@ -30,9 +35,33 @@ func Chol(M *Dense) (*CholFactors, bool) {
 
 // Chol conducts Cholesky decomposition for the matrix M.
 // The receiver ch is updated. Success flag is returned.
-func (ch *CholFactors) Chol(M *Dense) bool {
-	n := M.Rows()
-	if M.Cols() != n {
+// It is CholBlocked with the default block size and no parallelism.
+func (ch *CholFactors) Chol(M Matrix) bool {
+	return ch.CholBlocked(M, 0, 1)
+}
+
+// CholBlocked returns the Cholesky decomposition of the matrix M,
+// as Chol but by ch.CholBlocked.
+func CholBlocked(M Matrix, nb, workers int) (*CholFactors, bool) {
+	ch := &CholFactors{nil}
+	b := ch.CholBlocked(M, nb, workers)
+	return ch, b
+}
+
+// chol_block is the default block size of CholBlocked.
+const chol_block = 64
+
+// CholBlocked is Chol by the right-looking blocked algorithm of LAPACK
+// DPOTRF, which does most of the work in Dtrsm and Dsyrk of the
+// registered BLAS. Each step factorizes a diagonal block of nb rows,
+// then solves for the block col below it and updates the trailing
+// matrix. A block size nb <= 0 selects the default, 64.
+//
+// If workers > 1, the solves and updates of each step are split by rows
+// among that many goroutines, which call the BLAS concurrently.
+func (ch *CholFactors) CholBlocked(M Matrix, nb, workers int) bool {
+	n, c := M.Dims()
+	if c != n {
 		ch.l = nil
 		return false
 	}
@@ -47,39 +76,132 @@ func (ch *CholFactors) Chol(M *Dense) bool {
 	}
 
 	l := ch.l
+	if nb <= 0 {
+		nb = chol_block
+	}
 
 	// Typically Chol is called when the caller knows that
 	// M is symmetric and PD in concept, e.g. M is a covariance matrix.
-	// Hence symmetry is not checked directly.
+	// Hence symmetry is not checked directly, and only the lower
+	// triangle is read.
+	if d, ok := M.(*Dense); ok {
+		CopyLower(l, d)
+		CopyDiag(l, d)
+	} else {
+		for i := 0; i < n; i++ {
+			lRowi := l.RowView(i)
+			for j := 0; j <= i; j++ {
+				lRowi[j] = M.At(i, j)
+			}
+		}
+	}
 
+	for j := 0; j < n; j += nb {
+		jb := smaller(nb, n-j)
+		l11 := l.SubmatrixView(j, j, jb, jb)
+		if !chol_unblocked(l11) {
+			ch.l = nil
+			return false
+		}
+		below := n - j - jb
+		if below == 0 {
+			break
+		}
+
+		// L21 = A21 / L11'
+		l21 := l.SubmatrixView(j+jb, j, below, jb)
+		chol_split(below, workers, false, func(r0, r1 int) {
+			blasEngine.Dtrsm(blasOrder, blas.Right, blas.Lower, blas.Trans, blas.NonUnit,
+				r1-r0, jb, 1, l11.data, l11.stride,
+				l21.data[l21.idx(r0, 0):], l21.stride)
+		})
+
+		// A22 -= L21 * L21', in the lower triangle.
+		a22 := l.SubmatrixView(j+jb, j+jb, below, below)
+		chol_split(below, workers, true, func(r0, r1 int) {
+			if r0 > 0 {
+				blasEngine.Dgemm(blasOrder, blas.NoTrans, blas.Trans, r1-r0, r0, jb,
+					-1, l21.data[l21.idx(r0, 0):], l21.stride, l21.data, l21.stride,
+					1, a22.data[a22.idx(r0, 0):], a22.stride)
+			}
+			blasEngine.Dsyrk(blasOrder, blas.Lower, blas.NoTrans, r1-r0, jb,
+				-1, l21.data[l21.idx(r0, 0):], l21.stride,
+				1, a22.data[a22.idx(r0, r0):], a22.stride)
+		})
+	}
+
+	return true
+}
+
+// chol_unblocked overwrites the lower triangle of the square a by its
+// Cholesky factor, one dot product per element. It reports false if a
+// is not positive definite.
+func chol_unblocked(a *Dense) bool {
+	n := a.Rows()
 	for i := 0; i < n; i++ {
 		var d float64
-		lRowi := l.RowView(i)
+		aRowi := a.RowView(i)
 		for k := 0; k < i; k++ {
-			lRowk := l.RowView(k)
-			s := dot(lRowk[:k], lRowi[:k])
-			s = (M.Get(i, k) - s) / lRowk[k]
-			lRowi[k] = s
+			aRowk := a.RowView(k)
+			s := dot(aRowk[:k], aRowi[:k])
+			s = (aRowi[k] - s) / aRowk[k]
+			aRowi[k] = s
 			d += s * s
 		}
-		d = M.Get(i, i) - d
+		d = aRowi[i] - d
 		if d <= 0 {
-			ch.l = nil
 			return false
 		}
-		lRowi[i] = math.Sqrt(d)
+		aRowi[i] = math.Sqrt(d)
 	}
-
 	return true
 }
 
+// chol_split calls f(r0, r1) on a partition of the rows [0, n) into
+// ranges, one per worker, concurrently if workers > 1. If lower is
+// true, the work for a row is taken to grow with its index, as for the
+// lower triangle, and the ranges are balanced accordingly.
+func chol_split(n, workers int, lower bool, f func(r0, r1 int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		f(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	r0 := 0
+	for w := 1; w <= workers; w++ {
+		r1 := n * w / workers
+		if lower {
+			r1 = int(float64(n) * math.Sqrt(float64(w)/float64(workers)))
+		}
+		if w == workers {
+			r1 = n
+		}
+		if r1 <= r0 {
+			continue
+		}
+		wg.Add(1)
+		go func(r0, r1 int) {
+			defer wg.Done()
+			f(r0, r1)
+		}(r0, r1)
+		r0 = r1
+	}
+	wg.Wait()
+}
+
 // L returns the Cholesky factor L such that
 // L * L' = M, where M is the original matrix
 // that produced ch. Since the returned matrix is
-// a reference to internal data of ch, one is
+// a view of internal data of ch, one is
 // not expected to make changes to it.
-func (ch *CholFactors) L() *Dense {
-	return ch.l
+func (ch *CholFactors) L() *TriDense {
+	if ch.l == nil {
+		return nil
+	}
+	return TriDenseView(ch.l, blas.Lower, blas.NonUnit)
 }
 
 // Solve returns a matrix x that solves a * x = b where a is the matrix
@@ -88,55 +210,29 @@ func (ch *CholFactors) L() *Dense {
 // b is overwritten by the operation and returned containing the
 // solution.
 func (ch *CholFactors) Solve(b *Dense) *Dense {
+	return must_dense(ch.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (ch *CholFactors) TrySolve(b *Dense) (*Dense, error) {
 	l := ch.l
 	if l == nil {
-		panic(errInNil)
+		return nil, ErrInNil
 	}
 
 	n := l.Rows()
 
 	if b.Rows() != n {
-		panic(errShapes)
+		return nil, shape_error("CholFactors.Solve", ErrShapes, l, b)
 	}
 
-	x := b
-	nx := x.Cols()
-
 	// Solve L*Y = B;
-	for row := 0; row < n; row++ {
-		lrow := l.RowView(row)
-		for col := 0; col < nx; col++ {
-			ix := col
-			v := 0.0
-			for k := 0; k < row; k++ {
-				v += x.data[ix] * lrow[k]
-				ix += x.stride
-			}
-
-			// element (row, col) of x.
-			x.data[ix] = (x.data[ix] - v) / lrow[row]
-		}
-	}
+	SolveTri(ch.L(), false, b)
 
 	// Solve L'*X = Y;
-	for row := n - 1; row >= 0; row-- {
-		for col := 0; col < nx; col++ {
-			ix := x.idx(n-1, col)
-			// The col-th col of x.
-			il := l.idx(n-1, row)
-			v := 0.0
-			for k := n - 1; k > row; k-- {
-				v += x.data[ix] * l.data[il]
-				ix -= x.stride
-				il -= l.stride
-			}
-
-			// element (row, col) of x.
-			x.data[ix] = (x.data[ix] - v) / l.data[il]
-		}
-	}
+	SolveTri(ch.L(), true, b)
 
-	return x
+	return b, nil
 }
 
 // SolveR returns a matrix x that solves x * a = b where a is the matrix
@@ -145,70 +241,55 @@ func (ch *CholFactors) Solve(b *Dense) *Dense {
 // b is overwritten by the operation and returned containing the
 // solution.
 func (ch *CholFactors) SolveR(b *Dense) *Dense {
+	return must_dense(ch.TrySolveR(b))
+}
+
+// TrySolveR is SolveR returning an error instead of panicking.
+func (ch *CholFactors) TrySolveR(b *Dense) (*Dense, error) {
 	l := ch.l
 	if l == nil {
-		panic(errInNil)
+		return nil, ErrInNil
 	}
 
 	n := l.Cols()
 
 	if b.Cols() != n {
-		panic(errShapes)
+		return nil, shape_error("CholFactors.SolveR", ErrShapes, l, b)
 	}
 
-	x := b
-	nx := x.Rows()
-
-	// x * U' * U = B
+	// x * U' * U = B, where U = L'.
 
 	// Solve Y * U = B
-	for col := 0; col < n; col++ {
-		for row := 0; row < nx; row++ {
-			xrow := x.RowView(row)
-			lcol := l.RowView(col)
-			v := dot(xrow[:col], lcol[:col])
-			xrow[col] = (xrow[col] - v) / lcol[col]
-		}
-	}
+	SolveTriR(ch.L(), true, b)
 
 	// Solve X * U' = Y
-	for col := n - 1; col >= 0; col-- {
-		for row := 0; row < nx; row++ {
-			ix := x.idx(row, n-1)
-			il := l.idx(n-1, col)
-			v := 0.0
-			for k := n - 1; k > col; k-- {
-				v += x.data[ix] * l.data[il]
-				il -= l.stride
-				ix--
-			}
-			x.data[ix] = (x.data[ix] - v) / l.data[il]
-		}
-	}
+	SolveTriR(ch.L(), false, b)
 
-	return x
+	return b, nil
 }
 
 // Inv returns the inverse of the matrix a that produced ch by Chol(a).
 func (ch *CholFactors) Inv(out *Dense) *Dense {
+	return must_dense(ch.TryInv(out))
+}
+
+// TryInv is Inv returning an error instead of panicking.
+func (ch *CholFactors) TryInv(out *Dense) (*Dense, error) {
 	l := ch.l
 	if l == nil {
-		panic(errInNil)
+		return nil, ErrInNil
 	}
 
 	n := l.Rows()
 
-	if out == nil {
-		out = NewDense(n, n)
-	} else {
-		if out.Rows() != n || out.cols != n {
-			panic(errOutShape)
-		}
-		out.Fill(0.0)
+	out, e := try_use_dense("CholFactors.Inv", out, n, n)
+	if e != nil {
+		return nil, e
 	}
+	out.Fill(0.0)
 	out.FillDiag(1.0)
 
-	return ch.Solve(out)
+	return ch.TrySolve(out)
 }
 
 // Det returns the determinant of the matrix a that produced ch by Chol(a).
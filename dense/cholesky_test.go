@@ -5,6 +5,9 @@
 package dense
 
 import (
+	"math/rand"
+	"testing"
+
 	check "launchpad.net/gocheck"
 )
 
@@ -29,7 +32,7 @@ func (s *S) TestCholesky(c *check.C) {
 		c.Check(ok, check.Equals, t.spd)
 
 		c.Check(Approx(
-			Mult(cl.L(), T(cl.L(), nil), nil),
+			Mult(cl.L(), cl.L().TView(), nil),
 			t.a,
 			1e-12),
 			check.Equals, true)
@@ -79,3 +82,57 @@ func (s *S) TestCholesky(c *check.C) {
 			check.Equals, true)
 	}
 }
+
+// rand_spd returns a random n by n symmetric positive definite matrix.
+func rand_spd(n int) *Dense {
+	a, _ := randDense(n, 1, rand.NormFloat64)
+	return Mult(a, a.TView(), nil).Add(eye(n))
+}
+
+func (s *S) TestCholBlocked(c *check.C) {
+	a := rand_spd(70)
+	want, ok := CholBlocked(a, 1, 1)
+	c.Assert(ok, check.Equals, true)
+	c.Check(Approx(Mult(want.L(), want.L().TView(), nil), a, 1e-10), check.Equals, true)
+
+	for _, t := range []struct{ nb, workers int }{
+		{8, 1}, {8, 3}, {7, 4}, {64, 1}, {0, 2}, {200, 8},
+	} {
+		cl, ok := CholBlocked(a, t.nb, t.workers)
+		c.Check(ok, check.Equals, true)
+		c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true,
+			check.Commentf("nb %d, workers %d", t.nb, t.workers))
+	}
+
+	// Only the lower triangle is read, from any Matrix.
+	lo := Clone(a)
+	lo.FillUpper(0)
+	cl, _ := CholBlocked(lo, 8, 2)
+	c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true)
+	cl, _ = CholBlocked(T(lo, nil).TView(), 8, 2)
+	c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true)
+
+	// The factor of ch is reused, for a smaller matrix as well.
+	l := cl.l
+	c.Check(cl.CholBlocked(a.SubmatrixView(0, 0, 30, 30), 8, 2), check.Equals, true)
+	c.Check(&cl.l.data[0], check.Equals, &l.data[0])
+	c.Check(Approx(Mult(cl.L(), cl.L().TView(), nil), a.SubmatrixView(0, 0, 30, 30), 1e-10),
+		check.Equals, true)
+
+	// Not positive definite, failing in a later block.
+	a.Set(50, 50, -1)
+	c.Check(cl.CholBlocked(a, 8, 2), check.Equals, false)
+	c.Check(cl.L(), check.IsNil)
+}
+
+func BenchmarkChol500(b *testing.B)         { cholBench(b, 500, 1) }
+func BenchmarkChol500Parallel(b *testing.B) { cholBench(b, 500, 4) }
+func cholBench(b *testing.B, size, workers int) {
+	b.StopTimer()
+	a := rand_spd(size)
+	ch := &CholFactors{}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		ch.CholBlocked(a, 0, workers)
+	}
+}
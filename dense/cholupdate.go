@@ -0,0 +1,166 @@
+package dense
+
+import (
+	"math"
+)
+
+// Update modifies ch, the Cholesky factorization of M, into that of
+// M + x * x', in O(n^2) operations. x is not changed.
+func (ch *CholFactors) Update(x []float64) error {
+	if ch.l == nil {
+		return ErrInNil
+	}
+	if len(x) != ch.l.rows {
+		return ErrInLength
+	}
+	chol_update(ch.l, append([]float64(nil), x...))
+	return nil
+}
+
+// chol_update modifies the Cholesky factor l of M into that of
+// M + x * x' by Givens rotations. x is overwritten.
+func chol_update(l *Dense, x []float64) {
+	n := l.rows
+	for k := 0; k < n; k++ {
+		lkk := l.Get(k, k)
+		r := math.Hypot(lkk, x[k])
+		c, s := r/lkk, x[k]/lkk
+		l.Set(k, k, r)
+		for i := k + 1; i < n; i++ {
+			lRowi := l.RowView(i)
+			lRowi[k] = (lRowi[k] + s*x[i]) / c
+			x[i] = c*x[i] - s*lRowi[k]
+		}
+	}
+}
+
+// Downdate modifies ch, the Cholesky factorization of M, into that of
+// M - x * x', in O(n^2) operations. x is not changed.
+// If M - x * x' is not positive definite, Downdate returns ErrNotPosDef
+// and leaves ch unchanged.
+//
+// This is the algorithm of LINPACK DCHDD: with p = L \ x, the downdate
+// is possible if |p| < 1, and is done by the rotations that reduce
+// (p, sqrt(1 - |p|^2)) to a unit vector.
+func (ch *CholFactors) Downdate(x []float64) error {
+	l := ch.l
+	if l == nil {
+		return ErrInNil
+	}
+	n := l.rows
+	if len(x) != n {
+		return ErrInLength
+	}
+
+	p := append([]float64(nil), x...)
+	SolveTri(ch.L(), false, DenseView(p, n, 1))
+	alpha := 1 - dot(p, p)
+	if alpha <= 0 {
+		return ErrNotPosDef
+	}
+	alpha = math.Sqrt(alpha)
+
+	// The rotations, from the last.
+	c := make([]float64, n)
+	s := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		scale := alpha + math.Abs(p[i])
+		a, b := alpha/scale, p[i]/scale
+		norm := math.Hypot(a, b)
+		c[i], s[i] = a/norm, b/norm
+		alpha = scale * norm
+	}
+
+	// Apply them to each row of L, i.e. col of L'.
+	for j := 0; j < n; j++ {
+		lRowj := l.RowView(j)
+		var xx float64
+		for i := j; i >= 0; i-- {
+			t := c[i]*xx + s[i]*lRowj[i]
+			lRowj[i] = c[i]*lRowj[i] - s[i]*xx
+			xx = t
+		}
+	}
+
+	// Keep the diagonal positive.
+	for j := 0; j < n; j++ {
+		if l.Get(j, j) < 0 {
+			for i := j; i < n; i++ {
+				l.Set(i, j, -l.Get(i, j))
+			}
+		}
+	}
+	return nil
+}
+
+// Extend modifies ch, the Cholesky factorization of the n by n M, into
+// that of the n+1 by n+1 matrix that has M as its leading block, and
+// row (and col) n equal to row, which has length n+1.
+// If that matrix is not positive definite, Extend returns ErrNotPosDef
+// and leaves ch unchanged.
+func (ch *CholFactors) Extend(row []float64) error {
+	if ch.l == nil {
+		return ErrInNil
+	}
+	n := ch.l.rows
+	if len(row) != n+1 {
+		return ErrInLength
+	}
+
+	// The new row of L is [L \ row[:n], sqrt(row[n] - |L \ row[:n]|^2)].
+	l := NewDense(n+1, n+1)
+	lRown := l.RowView(n)
+	copy(lRown, row[:n])
+	if n > 0 {
+		SolveTri(ch.L(), false, DenseView(lRown[:n], n, 1))
+	}
+	d := row[n] - dot(lRown[:n], lRown[:n])
+	if d <= 0 {
+		return ErrNotPosDef
+	}
+	lRown[n] = math.Sqrt(d)
+
+	for i := 0; i < n; i++ {
+		copy(l.RowView(i)[:i+1], ch.l.RowView(i)[:i+1])
+	}
+	ch.l = l
+	return nil
+}
+
+// DeleteRowCol modifies ch, the Cholesky factorization of M, into that
+// of M with row and col k removed, in O(n^2) operations.
+func (ch *CholFactors) DeleteRowCol(k int) error {
+	if ch.l == nil {
+		return ErrInNil
+	}
+	n := ch.l.rows
+	if k < 0 || k >= n {
+		return ErrIndexOutOfRange
+	}
+
+	// With row and col k of L removed, the trailing block L33 must
+	// absorb the col below L[k][k]: it becomes the factor of
+	// L33 * L33' + l32 * l32'.
+	l := NewDense(n-1, n-1)
+	for i, ii := 0, 0; i < n; i++ {
+		if i == k {
+			continue
+		}
+		lRowi := ch.l.RowView(i)
+		dst := l.RowView(ii)
+		copy(dst, lRowi[:smaller(i+1, k)])
+		if i > k {
+			copy(dst[k:ii+1], lRowi[k+1:i+1])
+		}
+		ii++
+	}
+	if k < n-1 {
+		x := make([]float64, n-1-k)
+		for i := range x {
+			x[i] = ch.l.Get(k+1+i, k)
+		}
+		chol_update(l.SubmatrixView(k, k, n-1-k, n-1-k), x)
+	}
+	ch.l = l
+	return nil
+}
@@ -0,0 +1,82 @@
+package dense
+
+import (
+	"math/rand"
+
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestCholUpdate(c *check.C) {
+	const n = 12
+	a := rand_spd(n)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rand.NormFloat64()
+	}
+	xx := Mult(DenseView(x, n, 1), DenseView(x, 1, n), nil)
+
+	cl, _ := Chol(a)
+	c.Check(cl.Update(x), check.IsNil)
+	want, _ := Chol(Add(a, xx, nil))
+	c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true)
+
+	// And back.
+	c.Check(cl.Downdate(x), check.IsNil)
+	want, _ = Chol(a)
+	c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true)
+
+	// A downdate that loses positive definiteness changes nothing.
+	l := Clone(cl.L())
+	big := make([]float64, n)
+	for i := range big {
+		big[i] = 100 * x[i]
+	}
+	c.Check(cl.Downdate(big), check.Equals, ErrNotPosDef)
+	c.Check(Equal(cl.L(), l), check.Equals, true)
+
+	c.Check(cl.Update(x[1:]), check.Equals, ErrInLength)
+	c.Check(cl.Downdate(x[1:]), check.Equals, ErrInLength)
+	c.Check((&CholFactors{}).Update(x), check.Equals, ErrInNil)
+}
+
+func (s *S) TestCholExtendDelete(c *check.C) {
+	const n = 10
+	a := rand_spd(n)
+	full, _ := Chol(a)
+
+	// Build up from the leading 1 by 1 block.
+	cl, _ := Chol(a.SubmatrixView(0, 0, 1, 1))
+	for k := 1; k < n; k++ {
+		c.Check(cl.Extend(a.GetRow(k, nil)[:k+1]), check.IsNil)
+	}
+	c.Check(Approx(cl.L(), full.L(), 1e-10), check.Equals, true)
+
+	row := a.GetRow(0, nil)
+	c.Check(cl.Extend(append(row, 0)), check.Equals, ErrNotPosDef)
+	c.Check(Approx(cl.L(), full.L(), 1e-10), check.Equals, true)
+	c.Check(cl.Extend(row), check.Equals, ErrInLength)
+
+	for _, k := range []int{0, 4, n - 1} {
+		cl, _ := Chol(a)
+		c.Check(cl.DeleteRowCol(k), check.IsNil)
+
+		// a without row and col k.
+		b := NewDense(n-1, n-1)
+		for i, ii := 0, 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			for j, jj := 0, 0; j < n; j++ {
+				if j == k {
+					continue
+				}
+				b.Set(ii, jj, a.Get(i, j))
+				jj++
+			}
+			ii++
+		}
+		want, _ := Chol(b)
+		c.Check(Approx(cl.L(), want.L(), 1e-10), check.Equals, true, check.Commentf("k = %d", k))
+	}
+	c.Check(full.DeleteRowCol(n), check.Equals, ErrIndexOutOfRange)
+}
@@ -9,9 +9,19 @@ import (
 	"math"
 )
 
-var blasEngine blas.Float64
-
-func Register(b blas.Float64) { blasEngine = b }
+var blasEngine engine = native{}
+
+// Register installs b as the BLAS implementation used by this package,
+// e.g. a cgo binding to an optimized CBLAS.
+// Until Register is called, a pure-Go implementation is used.
+// Register(nil) restores the pure-Go implementation.
+func Register(b blas.Float64) {
+	if b == nil {
+		blasEngine = native{}
+		return
+	}
+	blasEngine = b
+}
 
 // This package uses row-major storage.
 // Every operation is affected by it.
@@ -45,15 +55,20 @@ func NewDense(r, c int) *Dense {
 // sense that the data is internal to the Dense.
 // This function does not allocate new memory.
 func DenseView(data []float64, r, c int) *Dense {
+	return must_dense(TryDenseView(data, r, c))
+}
+
+// TryDenseView is DenseView returning an error instead of panicking.
+func TryDenseView(data []float64, r, c int) (*Dense, error) {
 	if len(data) != r*c {
-		panic(errInLength)
+		return nil, ErrInLength
 	}
 	var m Dense
 	m.rows = r
 	m.cols = c
 	m.stride = c
 	m.data = data
-	return &m
+	return &m, nil
 }
 
 func (m *Dense) Dims() (r, c int) { return m.rows, m.cols }
@@ -90,21 +105,21 @@ func (m *Dense) Set(r, c int, v float64) *Dense {
 
 func (m *Dense) RowView(r int) []float64 {
 	if r >= m.rows || r < 0 {
-		panic(errIndexOutOfRange)
+		panic(ErrIndexOutOfRange)
 	}
 	k := r * m.stride
 	return m.data[k : k+m.cols]
 }
 
 func (m *Dense) GetRow(r int, row []float64) []float64 {
-	row = use_slice(row, m.cols, errOutLength)
+	row = use_slice(row, m.cols, ErrOutLength)
 	copy(row, m.RowView(r))
 	return row
 }
 
 func (m *Dense) SetRow(r int, v []float64) *Dense {
 	if len(v) != m.cols {
-		panic(errInLength)
+		panic(ErrInLength)
 	}
 	copy(m.RowView(r), v)
 	return m
@@ -112,7 +127,7 @@ func (m *Dense) SetRow(r int, v []float64) *Dense {
 
 func (m *Dense) ColView(c int) *Float64Stride {
 	if c >= m.cols || c < 0 {
-		panic(errIndexOutOfRange)
+		panic(ErrIndexOutOfRange)
 	}
 	from := c
 	to := from + (m.rows-1)*m.stride + 1
@@ -134,10 +149,10 @@ func (m *Dense) SetCol(c int, v []float64) *Dense {
 // because the view points to the data of the original matrix.
 func (m *Dense) SubmatrixView(i, j, r, c int) *Dense {
 	if i < 0 || i >= m.rows || r <= 0 || i+r > m.rows {
-		panic(errIndexOutOfRange)
+		panic(ErrIndexOutOfRange)
 	}
 	if j < 0 || j >= m.cols || c <= 0 || j+c > m.cols {
-		panic(errIndexOutOfRange)
+		panic(ErrIndexOutOfRange)
 	}
 
 	out := Dense{}
@@ -192,7 +207,7 @@ func (m *Dense) DataView() []float64 {
 // have the correct length.
 // The copied slice is returned.
 func (m *Dense) GetData(out []float64) []float64 {
-	out = use_slice(out, m.rows*m.cols, errOutLength)
+	out = use_slice(out, m.rows*m.cols, ErrOutLength)
 	if m.Contiguous() {
 		copy(out, m.DataView())
 	} else {
@@ -215,7 +230,7 @@ func (m *Dense) GetData(out []float64) []float64 {
 func (m *Dense) SetData(v []float64) *Dense {
 	r, c := m.rows, m.cols
 	if len(v) != r*c {
-		panic(errInLength)
+		panic(ErrInLength)
 	}
 	if m.Contiguous() {
 		copy(m.DataView(), v)
@@ -229,20 +244,29 @@ func (m *Dense) SetData(v []float64) *Dense {
 }
 
 func (m *Dense) Fill(v float64) *Dense {
-	element_wise_unary(m, v, m, fill)
+	element_wise_unary("Fill", m, v, m, fill)
 	return m
 }
 
-// DiagView returns a view into the diagonal elements.
-// The matrix does not have to be square.
+// DiagView returns a view into the min(rows, cols) diagonal elements.
+// The matrix does not have to be square. Since a Float64Stride can not
+// be empty, DiagView panics with ErrZeroLength if m has no rows or cols;
+// GetDiag, SetDiag and FillDiag accept such an m.
 func (m *Dense) DiagView() *Float64Stride {
-	return NewFloat64Stride(m.data, m.stride+1)
+	k := smaller(m.rows, m.cols)
+	if k == 0 {
+		panic(ErrZeroLength)
+	}
+	return NewFloat64Stride(m.data[:(k-1)*(m.stride+1)+1], m.stride+1)
 }
 
 // GetDiag copies diagonal elements of m into out.
 // out must have the correct length.
 // m is not required to be square.
 func (m *Dense) GetDiag(out []float64) []float64 {
+	if smaller(m.rows, m.cols) == 0 {
+		return use_slice(out, 0, ErrOutLength)
+	}
 	return m.DiagView().CopyToSlice(out)
 }
 
@@ -250,6 +274,12 @@ func (m *Dense) GetDiag(out []float64) []float64 {
 // The length of v must be exactly right.
 // m is not required to be square.
 func (m *Dense) SetDiag(v []float64) *Dense {
+	if smaller(m.rows, m.cols) == 0 {
+		if len(v) != 0 {
+			panic(ErrInLength)
+		}
+		return m
+	}
 	m.DiagView().CopyFromSlice(v)
 	return m
 }
@@ -257,6 +287,9 @@ func (m *Dense) SetDiag(v []float64) *Dense {
 // FillDiag sets all diagonal elements to value v.
 // The matrix m is not required to be square.
 func (m *Dense) FillDiag(v float64) *Dense {
+	if smaller(m.rows, m.cols) == 0 {
+		return m
+	}
 	m.DiagView().Fill(v)
 	return m
 }
@@ -264,17 +297,46 @@ func (m *Dense) FillDiag(v float64) *Dense {
 // Copy copies the elements of src into dest.
 // dest must have the correct dimensions; it can not be nil.
 // To create a new matrix and copy into it, use Clone.
-func Copy(dest *Dense, src *Dense) {
-	if dest.rows != src.rows || dest.cols != src.cols {
-		panic(errShapes)
+// src may be any Matrix, e.g. a Transpose view of a Dense,
+// in which case Copy materializes the view.
+// dest and src may share storage, e.g. be overlapping submatrix views
+// of the same matrix.
+func Copy(dest *Dense, src Matrix) {
+	if e := TryCopy(dest, src); e != nil {
+		panic(e)
+	}
+}
+
+// TryCopy is Copy returning an error instead of panicking.
+func TryCopy(dest *Dense, src Matrix) error {
+	r, c := src.Dims()
+	if dest.rows != r || dest.cols != c {
+		return shape_error("Copy", ErrShapes, dest, src)
+	}
+	if s, ok := src.(*Dense); ok && same(dest, s) {
+		return nil
+	}
+	if d, _, _, ok := unwrap(src); ok && overlap(dest, d) {
+		src = Clone(src)
 	}
-	if dest.Contiguous() && src.Contiguous() {
-		copy(dest.DataView(), src.DataView())
+	s, ok := src.(*Dense)
+	if !ok {
+		for row := 0; row < r; row++ {
+			drow := dest.RowView(row)
+			for col := range drow {
+				drow[col] = src.At(row, col)
+			}
+		}
+		return nil
+	}
+	if dest.Contiguous() && s.Contiguous() {
+		copy(dest.DataView(), s.DataView())
 	} else {
-		for row := 0; row < src.rows; row++ {
-			copy(dest.RowView(row), src.RowView(row))
+		for row := 0; row < r; row++ {
+			copy(dest.RowView(row), s.RowView(row))
 		}
 	}
+	return nil
 }
 
 // Clone creates a new Dense and copies the elements of src into it.
@@ -285,8 +347,8 @@ func Copy(dest *Dense, src *Dense) {
 // Note the "always allocate a new one" nature of Clone.
 // To copy into an existing matrix (including a submatrix),
 // use Copy instead.
-func Clone(src *Dense) *Dense {
-	out := NewDense(src.rows, src.cols)
+func Clone(src Matrix) *Dense {
+	out := NewDense(src.Dims())
 	Copy(out, src)
 	return out
 }
@@ -298,7 +360,7 @@ func Clone(src *Dense) *Dense {
 // Off-diagonal elements are not touched.
 func CopyDiag(dest, src *Dense) {
 	if dest.rows != src.rows || dest.cols != src.cols {
-		panic(errShapes)
+		panic(ErrShapes)
 	}
 	copy_stride(dest.DiagView(), src.DiagView())
 }
@@ -309,7 +371,7 @@ func CopyDiag(dest, src *Dense) {
 // square.
 func CopyUpper(dest, src *Dense) {
 	if dest.rows != src.rows || dest.cols != src.cols {
-		panic(errShapes)
+		panic(ErrShapes)
 	}
 	for row, k := 0, smaller(src.rows, src.cols); row < k-1; row++ {
 		copy(dest.RowView(row)[row+1:], src.RowView(row)[row+1:])
@@ -326,7 +388,7 @@ func CopyUpper(dest, src *Dense) {
 // square.
 func CopyLower(dest, src *Dense) {
 	if dest.rows != src.rows || dest.cols != src.cols {
-		panic(errShapes)
+		panic(ErrShapes)
 	}
 	for row := 1; row < src.rows; row++ {
 		k := smaller(row, src.cols)
@@ -362,25 +424,34 @@ func (m *Dense) FillUpper(v float64) *Dense {
 	return m
 }
 
-func element_wise_unary(a *Dense, val float64, out *Dense,
-	f func(a []float64, val float64, out []float64) []float64) *Dense {
+func element_wise_unary(op string, a *Dense, val float64, out *Dense,
+	f func(a []float64, val float64, out []float64) []float64) (*Dense, error) {
 
-	out = use_dense(out, a.rows, a.cols, errOutShape)
+	out, e := try_use_dense(op, out, a.rows, a.cols)
+	if e != nil {
+		return nil, e
+	}
+	a = unalias(out, a)
 	if a.Contiguous() && out.Contiguous() {
 		f(a.DataView(), val, out.DataView())
-		return out
+		return out, nil
 	}
 	for row := 0; row < a.rows; row++ {
 		f(a.RowView(row), val, out.RowView(row))
 	}
-	return out
+	return out, nil
 }
 
 // Shift adds constant v to every element of m,
 // returns the new values in matrix out.
 // out may be m itself, amounting to in-place update.
 func Shift(m *Dense, v float64, out *Dense) *Dense {
-	return element_wise_unary(m, v, out, shift)
+	return must_dense(TryShift(m, v, out))
+}
+
+// TryShift is Shift returning an error instead of panicking.
+func TryShift(m *Dense, v float64, out *Dense) (*Dense, error) {
+	return element_wise_unary("Shift", m, v, out, shift)
 }
 
 // Shift adds constant v to each element of m.
@@ -393,7 +464,12 @@ func (m *Dense) Shift(v float64) *Dense {
 // returns the new values in matrix out.
 // out may be m itself, amounting to in-place update.
 func Scale(m *Dense, v float64, out *Dense) *Dense {
-	return element_wise_unary(m, v, out, scale)
+	return must_dense(TryScale(m, v, out))
+}
+
+// TryScale is Scale returning an error instead of panicking.
+func TryScale(m *Dense, v float64, out *Dense) (*Dense, error) {
+	return element_wise_unary("Scale", m, v, out, scale)
 }
 
 // Scale multiplies each element of m by constant v.
@@ -402,21 +478,25 @@ func (m *Dense) Scale(v float64) *Dense {
 	return Scale(m, v, m)
 }
 
-func element_wise_binary(a, b, out *Dense,
-	f func(a, b, out []float64) []float64) *Dense {
+func element_wise_binary(op string, a, b, out *Dense,
+	f func(a, b, out []float64) []float64) (*Dense, error) {
 
 	if a.rows != b.rows || a.cols != b.cols {
-		panic(errShapes)
+		return nil, shape_error(op, ErrShapes, a, b)
+	}
+	out, e := try_use_dense(op, out, a.rows, a.cols)
+	if e != nil {
+		return nil, e
 	}
-	out = use_dense(out, a.rows, a.cols, errOutShape)
+	a, b = unalias(out, a), unalias(out, b)
 	if a.Contiguous() && b.Contiguous() && out.Contiguous() {
 		f(a.DataView(), b.DataView(), out.DataView())
-		return out
+		return out, nil
 	}
 	for row := 0; row < a.rows; row++ {
 		f(a.RowView(row), b.RowView(row), out.RowView(row))
 	}
-	return out
+	return out, nil
 }
 
 // Add adds matrices a and b, place the result in out and return out.
@@ -424,8 +504,15 @@ func element_wise_binary(a, b, out *Dense,
 // If out is non-nil, it must have the correct shape.
 // out may be one of a and b, that is, add a and b and place the result
 // in a (or b, depending on which one out is).
+// If out partially overlaps a or b, e.g. as submatrix views of the same
+// matrix, the overlapping input is copied first.
 func Add(a, b, out *Dense) *Dense {
-	return element_wise_binary(a, b, out, add)
+	return must_dense(TryAdd(a, b, out))
+}
+
+// TryAdd is Add returning an error instead of panicking.
+func TryAdd(a, b, out *Dense) (*Dense, error) {
+	return element_wise_binary("Add", a, b, out, add)
 }
 
 // Add adds matrix X to the receiver matrix.
@@ -442,18 +529,27 @@ func (m *Dense) Add(X *Dense) *Dense {
 // out may be one of a and b, that is, one of the input matrices holds
 // the result.
 func AddScaled(a, b *Dense, s float64, out *Dense) *Dense {
+	return must_dense(TryAddScaled(a, b, s, out))
+}
+
+// TryAddScaled is AddScaled returning an error instead of panicking.
+func TryAddScaled(a, b *Dense, s float64, out *Dense) (*Dense, error) {
 	if a.rows != b.rows || a.cols != b.cols {
-		panic(errShapes)
+		return nil, shape_error("AddScaled", ErrShapes, a, b)
 	}
-	out = use_dense(out, a.rows, a.cols, errOutShape)
+	out, e := try_use_dense("AddScaled", out, a.rows, a.cols)
+	if e != nil {
+		return nil, e
+	}
+	a, b = unalias(out, a), unalias(out, b)
 	if a.Contiguous() && b.Contiguous() && out.Contiguous() {
 		add_scaled(a.DataView(), b.DataView(), s, out.DataView())
-		return out
+		return out, nil
 	}
 	for row := 0; row < a.rows; row++ {
 		add_scaled(a.RowView(row), b.RowView(row), s, out.RowView(row))
 	}
-	return out
+	return out, nil
 }
 
 // AddScaled adds X scaled by constant s to the receiver matrix.
@@ -464,7 +560,12 @@ func (m *Dense) AddScaled(X *Dense, s float64) *Dense {
 
 // Subtract is analogous to Add.
 func Subtract(a, b, out *Dense) *Dense {
-	return element_wise_binary(a, b, out, subtract)
+	return must_dense(TrySubtract(a, b, out))
+}
+
+// TrySubtract is Subtract returning an error instead of panicking.
+func TrySubtract(a, b, out *Dense) (*Dense, error) {
+	return element_wise_binary("Subtract", a, b, out, subtract)
 }
 
 // Subtract is analogous to Add.
@@ -475,7 +576,12 @@ func (m *Dense) Subtract(X *Dense) *Dense {
 // Elemult does element-wise multiplication in a way analogous to Add
 // and Subtract.
 func Elemult(a, b, out *Dense) *Dense {
-	return element_wise_binary(a, b, out, multiply)
+	return must_dense(TryElemult(a, b, out))
+}
+
+// TryElemult is Elemult returning an error instead of panicking.
+func TryElemult(a, b, out *Dense) (*Dense, error) {
+	return element_wise_binary("Elemult", a, b, out, multiply)
 }
 
 // Elemult does element-wise multiplication in a way analogous to Add
@@ -486,65 +592,161 @@ func (m *Dense) Elemult(X *Dense) *Dense {
 
 // Mult multiplies matrices a and b, place the result in out, and return
 // out. If out is nil, a new matrix is allocated and used.
-// TODO: find out whether out can be one of a and b.
-func Mult(a, b, out *Dense) *Dense {
+// If a or b is a Transpose or Scaled view of a Dense, the view is
+// handed to the BLAS engine as is, without making a copy.
+// out may share storage with a or b; the product is then computed
+// in a temporary matrix and copied into out.
+func Mult(a, b Matrix, out *Dense) *Dense {
+	return must_dense(TryMult(a, b, out))
+}
+
+// TryMult is Mult returning an error instead of panicking.
+func TryMult(a, b Matrix, out *Dense) (*Dense, error) {
+	return gemm("Mult", 1, a, b, 0, out)
+}
+
+// MultTrans computes a * b', places the result in out, and returns out.
+// It is the same as Mult(a, Transpose{b}, out).
+func MultTrans(a, b Matrix, out *Dense) *Dense {
+	return must_dense(gemm("MultTrans", 1, a, Transpose{b}, 0, out))
+}
+
+// TransMult computes a' * b, places the result in out, and returns out.
+// It is the same as Mult(Transpose{a}, b, out).
+func TransMult(a, b Matrix, out *Dense) *Dense {
+	return must_dense(gemm("TransMult", 1, Transpose{a}, b, 0, out))
+}
+
+// Gemm computes
+//    c = alpha * op(a) * op(b) + beta * c
+// places the result in c, and returns c.
+// op(a) is a' if transA is true, and a otherwise;
+// likewise for op(b).
+// If c is nil, a new matrix is allocated and used, and beta is
+// irrelevant; otherwise c must have the correct shape.
+// Any of a, b, and c may be a submatrix view.
+// This maps directly onto Dgemm of the registered BLAS engine.
+func Gemm(transA, transB bool, alpha float64, a, b *Dense, beta float64, c *Dense) *Dense {
+	return must_dense(TryGemm(transA, transB, alpha, a, b, beta, c))
+}
+
+// TryGemm is Gemm returning an error instead of panicking.
+func TryGemm(transA, transB bool, alpha float64, a, b *Dense, beta float64, c *Dense) (*Dense, error) {
+	var x, y Matrix = a, b
+	if transA {
+		x = Transpose{a}
+	}
+	if transB {
+		y = Transpose{b}
+	}
+	return gemm("Gemm", alpha, x, y, beta, c)
+}
+
+// gemm computes alpha * a * b + beta * c by Dgemm, where a and b
+// are Transpose or Scaled views of a Dense, or any other Matrix,
+// which is then copied first. If either of a and b is a SymDense,
+// Dsymm is used instead.
+// If c is nil, a new matrix is allocated.
+func gemm(op string, alpha float64, a, b Matrix, beta float64, c *Dense) (*Dense, error) {
 	ar, ac := a.Dims()
 	br, bc := b.Dims()
 
 	if ac != br {
-		panic(errShapes)
+		return nil, shape_error(op, ErrShapes, a, b)
 	}
 
-	out = use_dense(out, ar, bc, errOutShape)
+	c, e := try_use_dense(op, c, ar, bc)
+	if e != nil {
+		return nil, e
+	}
 
-	if blasEngine == nil {
-		panic(errNoEngine)
+	// A symmetric operand goes to Dsymm.
+	if s, ok := a.(*SymDense); ok {
+		return symm(blas.Left, alpha, s, b, beta, c), nil
 	}
+	if s, ok := b.(*SymDense); ok {
+		return symm(blas.Right, alpha, s, a, beta, c), nil
+	}
+
+	ad, ta, aalpha := blas_operand(a)
+	bd, tb, balpha := blas_operand(b)
+
+	// Dgemm requires c to be distinct from a and b.
+	out := scratch_out(c, beta != 0, ad, bd)
+
 	blasEngine.Dgemm(
 		blasOrder,
-		blas.NoTrans, blas.NoTrans,
+		ta, tb,
 		ar, bc, ac,
-		1.,
-		a.data, a.stride,
-		b.data, b.stride,
-		0.,
+		alpha*aalpha*balpha,
+		ad.data, ad.stride,
+		bd.data, bd.stride,
+		beta,
 		out.data, out.stride)
 
-	return out
+	flush_out(c, out)
+	return c, nil
 }
 
 func Dot(a, b *Dense) float64 {
+	d, e := TryDot(a, b)
+	if e != nil {
+		panic(e)
+	}
+	return d
+}
+
+// TryDot is Dot returning an error instead of panicking.
+func TryDot(a, b *Dense) (float64, error) {
 	if a.rows != b.rows || a.cols != b.cols {
-		panic(errShapes)
+		return 0, shape_error("Dot", ErrShapes, a, b)
 	}
 	if a.Contiguous() && b.Contiguous() {
-		return dot(a.DataView(), b.DataView())
+		return dot(a.DataView(), b.DataView()), nil
 	}
 	d := 0.0
 	for row := 0; row < a.rows; row++ {
 		d += dot(a.RowView(row), b.RowView(row))
 	}
-	return d
+	return d, nil
 }
 
 func Hstack(a, b, out *Dense) *Dense {
+	return must_dense(TryHstack(a, b, out))
+}
+
+// TryHstack is Hstack returning an error instead of panicking.
+func TryHstack(a, b, out *Dense) (*Dense, error) {
 	if a.rows != b.rows {
-		panic(errShapes)
+		return nil, shape_error("Hstack", ErrShapes, a, b)
+	}
+	out, e := try_use_dense("Hstack", out, a.rows, a.cols+b.cols)
+	if e != nil {
+		return nil, e
 	}
-	out = use_dense(out, a.rows, a.cols+b.cols, errOutShape)
+	a, b = unalias(out, a), unalias(out, b)
 	Copy(out.SubmatrixView(0, 0, a.rows, a.cols), a)
 	Copy(out.SubmatrixView(0, a.cols, b.rows, b.cols), b)
-	return out
+	return out, nil
 }
 
 func Vstack(a, b, out *Dense) *Dense {
+	return must_dense(TryVstack(a, b, out))
+}
+
+// TryVstack is Vstack returning an error instead of panicking.
+func TryVstack(a, b, out *Dense) (*Dense, error) {
 	if a.cols != b.cols {
-		panic(errShapes)
+		return nil, shape_error("Vstack", ErrShapes, a, b)
+	}
+	out, e := try_use_dense("Vstack", out, a.rows+b.rows, a.cols)
+	if e != nil {
+		return nil, e
 	}
-	out = use_dense(out, a.rows+b.rows, a.cols, errOutShape)
+	a, b = unalias(out, a), unalias(out, b)
 	Copy(out.SubmatrixView(0, 0, a.rows, a.cols), a)
 	Copy(out.SubmatrixView(a.rows, 0, b.rows, b.cols), b)
-	return out
+	return out, nil
 }
 
 // Min returns the minimum element of m.
@@ -590,14 +792,32 @@ func (m *Dense) Sum() float64 {
 }
 
 func (m *Dense) Trace() float64 {
+	t, e := m.TryTrace()
+	if e != nil {
+		panic(e)
+	}
+	return t
+}
+
+// TryTrace is Trace returning an error instead of panicking.
+func (m *Dense) TryTrace() (float64, error) {
 	if m.rows != m.cols {
-		panic(errSquare)
+		return 0, shape_error("Trace", ErrSquare, m)
 	}
-	return m.DiagView().Sum()
+	return m.DiagView().Sum(), nil
 }
 
 // Norm returns the order ord of norm for matrix m.
 func (m *Dense) Norm(ord float64) float64 {
+	n, e := m.TryNorm(ord)
+	if e != nil {
+		panic(e)
+	}
+	return n
+}
+
+// TryNorm is Norm returning an error instead of panicking.
+func (m *Dense) TryNorm(ord float64) (float64, error) {
 	var n float64
 	switch {
 	case ord == 1:
@@ -632,10 +852,10 @@ func (m *Dense) Norm(ord float64) float64 {
 			n = s[len(s)-1]
 		}
 	default:
-		panic(errNormOrder)
+		return 0, ErrNormOrder
 	}
 
-	return n
+	return n, nil
 }
 
 // Apply applies function f to each element of m, place the results in
@@ -652,7 +872,20 @@ func Apply(
 	f func(r, c int, v float64) float64,
 	out *Dense) *Dense {
 
-	out = use_dense(out, m.rows, m.cols, errOutShape)
+	return must_dense(TryApply(m, f, out))
+}
+
+// TryApply is Apply returning an error instead of panicking.
+func TryApply(
+	m *Dense,
+	f func(r, c int, v float64) float64,
+	out *Dense) (*Dense, error) {
+
+	out, e := try_use_dense("Apply", out, m.rows, m.cols)
+	if e != nil {
+		return nil, e
+	}
+	m = unalias(out, m)
 	for row := 0; row < m.rows; row++ {
 		in_row := m.RowView(row)
 		out_row := out.RowView(row)
@@ -660,7 +893,7 @@ func Apply(
 			out_row[col] = f(row, col, z)
 		}
 	}
-	return out
+	return out, nil
 }
 
 // Apply updates each element of m by calling the function f,
@@ -675,8 +908,18 @@ func (m *Dense) Apply(f func(int, int, float64) float64) *Dense {
 // If out is nil, a new matrix is allocated and used;
 // otherwise out must have the correct shape.
 // If m is square, out can be m itself.
+// If out otherwise shares storage with m, m is copied first.
 func T(m, out *Dense) *Dense {
-	out = use_dense(out, m.cols, m.rows, errOutShape)
+	return must_dense(TryT(m, out))
+}
+
+// TryT is T returning an error instead of panicking.
+func TryT(m, out *Dense) (*Dense, error) {
+	out, e := try_use_dense("T", out, m.cols, m.rows)
+	if e != nil {
+		return nil, e
+	}
+	m = unalias(out, m)
 	if m.rows == m.cols {
 		for row := 0; row < m.rows; row++ {
 			for col := 0; col < row; col++ {
@@ -691,44 +934,58 @@ func T(m, out *Dense) *Dense {
 			out.SetCol(row, m.RowView(row))
 		}
 	}
-	return out
+	return out, nil
 }
 
 // T transposes the square receiver matrix in-place,
 // and also returns the transposed matrix.
 func (m *Dense) T() *Dense {
 	if m.rows != m.cols {
-		panic(errSquare)
+		panic(shape_error("T", ErrSquare, m))
 	}
 	T(m, m)
 	return m
 }
 
-func Equal(a, b *Dense) bool {
-	if a.rows != b.rows || a.cols != b.cols {
-		return false
-	}
-	if a.Contiguous() && b.Contiguous() {
-		return all_equal(a.DataView(), b.DataView())
-	}
-	for row := 0; row < a.rows; row++ {
-		if !all_equal(a.RowView(row), b.RowView(row)) {
-			return false
-		}
-	}
-	return true
+func Equal(a, b Matrix) bool {
+	return compare(a, b, equal, all_equal)
 }
 
-func Approx(a, b *Dense, eps float64) bool {
-	if a.rows != b.rows || a.cols != b.cols {
+func Approx(a, b Matrix, eps float64) bool {
+	return compare(a, b,
+		func(x, y float64) bool { return approx(x, y, eps) },
+		func(x, y []float64) bool { return all_approx(x, y, eps) })
+}
+
+// compare reports whether a and b have the same shape and
+// every pair of corresponding elements satisfies f.
+// If both a and b are *Dense, the rows are compared by g.
+func compare(a, b Matrix,
+	f func(x, y float64) bool, g func(x, y []float64) bool) bool {
+
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ar != br || ac != bc {
 		return false
 	}
-	if a.Contiguous() && b.Contiguous() {
-		return all_approx(a.DataView(), b.DataView(), eps)
+	ad, aok := a.(*Dense)
+	bd, bok := b.(*Dense)
+	if aok && bok {
+		if ad.Contiguous() && bd.Contiguous() {
+			return g(ad.DataView(), bd.DataView())
+		}
+		for row := 0; row < ar; row++ {
+			if !g(ad.RowView(row), bd.RowView(row)) {
+				return false
+			}
+		}
+		return true
 	}
-	for row := 0; row < a.rows; row++ {
-		if !all_approx(a.RowView(row), b.RowView(row), eps) {
-			return false
+	for row := 0; row < ar; row++ {
+		for col := 0; col < ac; col++ {
+			if !f(a.At(row, col), b.At(row, col)) {
+				return false
+			}
 		}
 	}
 	return true
@@ -739,35 +996,66 @@ func (m *Dense) Det() float64 {
 	return LU(Clone(m)).Det()
 }
 
+// TryDet is Det returning an error instead of panicking.
+func (m *Dense) TryDet() (float64, error) {
+	if m.rows != m.cols {
+		return 0, shape_error("Det", ErrSquare, m)
+	}
+	return m.Det(), nil
+}
+
 // Inv returns the inverse or pseudoinverse of the matrix a.
 //
 // Within this function, a is modified.
 // If this is not desired, pass in a clone of the source matrix.
 func Inv(a *Dense, out *Dense) *Dense {
+	return must_dense(TryInv(a, out))
+}
+
+// TryInv is Inv returning an error instead of panicking.
+func TryInv(a *Dense, out *Dense) (*Dense, error) {
 	if out == nil {
 		out = eye(a.rows)
 	} else {
 		if out.rows != a.rows || a.cols != a.rows {
-			panic(errOutShape)
+			return nil, &ShapeError{"Inv", ErrOutShape,
+				[][2]int{{a.rows, a.rows}, {out.rows, out.cols}}}
 		}
 		out.Fill(0.0)
 		out.FillDiag(1.0)
 	}
-	return Solve(a, out)
+	return TrySolve(a, out)
 }
 
 // Solve returns a matrix x that satisfies ax = b,
-// that is, it returns inv(a) b.
+// that is, it returns inv(a) b. If a is tall, x is the least squares
+// solution by QR; if a is wide, x is the minimum norm solution by LQ.
 //
 // Within this function, both a and b are modified;
-// b becomes the returned solution matrix.
+// unless a is wide, b becomes the returned solution matrix.
 // If these modifications are not desired,
 // pass in clones of the source matrices of a and b.
 func Solve(a, b *Dense) *Dense {
+	return must_dense(TrySolve(a, b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+// In particular, the error is ErrSingular if a is square and
+// singular, and ErrRankDeficient if a is not square and not of full rank.
+func TrySolve(a, b *Dense) (*Dense, error) {
 	if a.rows == a.cols {
-		b = LU(a).Solve(b)
-	} else {
-		b = QR(a).Solve(b)
+		return LU(a).TrySolve(b)
+	}
+	if a.rows < a.cols {
+		f, e := TryLQ(a)
+		if e != nil {
+			return nil, e
+		}
+		return f.TrySolve(b)
+	}
+	f, e := TryQR(a)
+	if e != nil {
+		return nil, e
 	}
-	return b
+	return f.TrySolve(b)
 }
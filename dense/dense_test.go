@@ -5,8 +5,8 @@
 package dense
 
 import (
+	"errors"
 	"fmt"
-	"github.com/gonum/blas/cblas"
 	check "launchpad.net/gocheck"
 	"math"
 	"math/rand"
@@ -39,8 +39,6 @@ func panics(fn func()) (panicked bool, message string) {
 	return
 }
 
-func (s *S) SetUpSuite(c *check.C) { blasEngine = cblas.Blas{} }
-
 func (s *S) TestMaybe(c *check.C) {
 	for i, test := range []struct {
 		fn     panicker
@@ -221,6 +219,52 @@ func (s *S) TestSetRowColumn(c *check.C) {
 	}
 }
 
+func (s *S) TestDiag(c *check.C) {
+	big := make_dense(6, 8, make([]float64, 48))
+	for _, t := range []struct {
+		name string
+		m    *Dense
+	}{
+		{"tall", NewDense(40, 25)},
+		{"wide", NewDense(3, 7)},
+		{"square", NewDense(4, 4)},
+		{"tall view", big.SubmatrixView(1, 2, 5, 3)},
+		{"wide view", big.SubmatrixView(0, 1, 3, 6)},
+	} {
+		comment := check.Commentf("%s", t.name)
+		m := t.m
+		r, cols := m.Dims()
+		k := smaller(r, cols)
+		c.Check(m.DiagView().Len(), check.Equals, k, comment)
+
+		want := make([]float64, k)
+		for i := range want {
+			want[i] = float64(i + 1)
+		}
+		m.SetDiag(want)
+		c.Check(m.GetDiag(nil), check.DeepEquals, want, comment)
+		m.FillDiag(-1)
+		for i := 0; i < r; i++ {
+			for j := 0; j < cols; j++ {
+				v := 0.0
+				if i == j {
+					v = -1
+				}
+				c.Check(m.Get(i, j), check.Equals, v, check.Commentf("%s (%d, %d)", t.name, i, j))
+			}
+		}
+		m.FillDiag(0)
+	}
+	// Nothing outside the views was touched.
+	c.Check(Equal(big, NewDense(6, 8)), check.Equals, true)
+
+	// Empty matrices.
+	e := NewDense(0, 3)
+	c.Check(e.GetDiag(nil), check.HasLen, 0)
+	e.FillDiag(1).SetDiag(nil)
+	c.Check(func() { e.DiagView() }, check.Panics, ErrZeroLength)
+}
+
 func (s *S) TestAdd(c *check.C) {
 	for i, test := range []struct {
 		a, b, r [][]float64
@@ -419,7 +463,7 @@ func (s *S) TestLU(c *check.C) {
 		size := rand.Intn(100)
 		r, err := randDense(size, rand.Float64(), rand.NormFloat64)
 		if size == 0 {
-			c.Check(err, check.Equals, errZeroLength)
+			c.Check(err, check.Equals, ErrZeroLength)
 			continue
 		}
 		c.Assert(err, check.Equals, nil)
@@ -711,7 +755,7 @@ func (s *S) TestSolve(c *check.C) {
 		},
 		{
 			name:   "WideMatrix",
-			panics: true,
+			panics: false,
 			a: [][]float64{
 				{0.8147, 0.9134, 0.5528},
 				{0.9058, 0.6324, 0.8723},
@@ -721,8 +765,9 @@ func (s *S) TestSolve(c *check.C) {
 				{0.547},
 			},
 			x: [][]float64{
-				{1.037422650449745},
-				{-0.620963688768783},
+				{0.25919787248965376},
+				{-0.25560256266441095},
+				{0.5432324059702459},
 			},
 		},
 
@@ -792,6 +837,62 @@ func (s *S) TestSolve(c *check.C) {
 	}
 }
 
+func (s *S) TestTryErrors(c *check.C) {
+	a := NewDense(2, 3)
+	b := NewDense(2, 2)
+
+	_, e := TryMult(a, b, nil)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	var se *ShapeError
+	c.Assert(errors.As(e, &se), check.Equals, true)
+	c.Check(se.Op, check.Equals, "Mult")
+	c.Check(se.Dims, check.DeepEquals, [][2]int{{2, 3}, {2, 2}})
+	c.Check(e.Error(), check.Equals, "dense: shape mismatch in Mult: 2x3, 2x2")
+
+	_, e = TryMult(b, a, NewDense(3, 3))
+	c.Check(errors.Is(e, ErrOutShape), check.Equals, true)
+	c.Assert(errors.As(e, &se), check.Equals, true)
+	c.Check(se.Dims, check.DeepEquals, [][2]int{{2, 3}, {3, 3}})
+
+	out, e := TryMult(b, a, nil)
+	c.Check(e, check.IsNil)
+	c.Check(out.rows, check.Equals, 2)
+	c.Check(out.cols, check.Equals, 3)
+
+	_, e = TryAdd(a, b, nil)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	_, e = TryHstack(a, NewDense(3, 1), nil)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	_, e = TryVstack(a, b, nil)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	_, e = TryT(a, NewDense(2, 3))
+	c.Check(errors.Is(e, ErrOutShape), check.Equals, true)
+	c.Check(errors.Is(TryCopy(a, b), ErrShapes), check.Equals, true)
+	_, e = TryDot(a, b)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	_, e = a.TryTrace()
+	c.Check(errors.Is(e, ErrSquare), check.Equals, true)
+	_, e = a.TryNorm(3)
+	c.Check(e, check.Equals, ErrNormOrder)
+	_, e = TryDenseView(make([]float64, 5), 2, 3)
+	c.Check(e, check.Equals, ErrInLength)
+
+	singular := make_dense(2, 2, []float64{1, 2, 2, 4})
+	_, e = TrySolve(singular, NewDense(2, 1))
+	c.Check(e, check.Equals, ErrSingular)
+	_, e = TryInv(make_dense(2, 2, []float64{1, 2, 2, 4}), nil)
+	c.Check(e, check.Equals, ErrSingular)
+	_, e = TryQR(a)
+	c.Check(errors.Is(e, ErrInShape), check.Equals, true)
+	_, e = TrySolve(make_dense(3, 2, []float64{1, 0, 2, 0, 3, 0}), NewDense(3, 1))
+	c.Check(e, check.Equals, ErrRankDeficient)
+
+	// The panicking functions panic with the same error.
+	panicked, message := panics(func() { Mult(a, b, nil) })
+	c.Check(panicked, check.Equals, true)
+	c.Check(message, check.Equals, "dense: shape mismatch in Mult: 2x3, 2x2")
+}
+
 var (
 	wd *Dense
 )
@@ -827,3 +928,52 @@ func densePreMulBench(b *testing.B, size int, rho float64) {
 		wd = Mult(a, d, nil)
 	}
 }
+
+func (s *S) TestGemm(c *check.C) {
+	for _, tA := range []bool{false, true} {
+		for _, tB := range []bool{false, true} {
+			// op(a) is 4 by 3, op(b) is 3 by 5,
+			// taken as submatrix views of larger matrices.
+			abig := rand_dense(7, 8)
+			bbig := rand_dense(9, 6)
+			cbig := rand_dense(6, 9)
+			var a, b *Dense
+			if tA {
+				a = abig.SubmatrixView(1, 2, 3, 4)
+			} else {
+				a = abig.SubmatrixView(1, 2, 4, 3)
+			}
+			if tB {
+				b = bbig.SubmatrixView(3, 1, 5, 3)
+			} else {
+				b = bbig.SubmatrixView(3, 1, 3, 5)
+			}
+			cc := cbig.SubmatrixView(1, 3, 4, 5)
+			orig := Clone(cbig)
+
+			want := Clone(cc)
+			naive_gemm(tA, tB, 2.5, a, b, -1.5, want)
+			Gemm(tA, tB, 2.5, a, b, -1.5, cc)
+			c.Check(Approx(cc, want, 1e-12), check.Equals, true,
+				check.Commentf("tA %v, tB %v", tA, tB))
+
+			// Elements outside of the view are untouched.
+			Copy(cc, orig.SubmatrixView(1, 3, 4, 5))
+			c.Check(Equal(cbig, orig), check.Equals, true)
+		}
+	}
+
+	a := rand_dense(4, 3)
+	b := rand_dense(5, 3)
+	c.Check(Approx(MultTrans(a, b, nil), Mult(a, T(b, nil), nil), 1e-12),
+		check.Equals, true)
+	c.Check(Approx(TransMult(b, Clone(b), nil), Mult(T(b, nil), b, nil), 1e-12),
+		check.Equals, true)
+	c.Check(Approx(Gemm(false, true, 1, a, b, 0, nil), MultTrans(a, b, nil), 1e-12),
+		check.Equals, true)
+
+	_, e := TryGemm(false, false, 1, a, b, 0, nil)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+	_, e = TryGemm(false, true, 1, a, b, 0, NewDense(5, 4))
+	c.Check(errors.Is(e, ErrOutShape), check.Equals, true)
+}
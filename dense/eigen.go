@@ -24,6 +24,7 @@ func symmetric(m *Dense) bool {
 type EigenFactors struct {
 	V    *Dense
 	d, e []float64
+	sym  bool
 }
 
 // Eigen returns the Eigenvalues and eigenvectors of a square real matrix.
@@ -38,17 +39,33 @@ type EigenFactors struct {
 // i.e. a.v equals v.D. The matrix v may be badly conditioned, or even
 // singular, so the validity of the equation a = v*D*inverse(v) depends
 // upon the 2-norm condition number of v.
-func Eigen(a *Dense, epsilon float64) EigenFactors {
-	m, n := a.Dims()
+// If a is not a *Dense, it is copied first and left unchanged.
+// If a is a *SymDense, it is known to be symmetric and is not checked.
+// For selected eigenpairs of a symmetric matrix, in a chosen order, see
+// EigenSym.
+func Eigen(a Matrix, epsilon float64) EigenFactors {
+	f, e := TryEigen(a, epsilon)
+	if e != nil {
+		panic(e)
+	}
+	return f
+}
+
+// TryEigen is Eigen returning an error instead of panicking.
+func TryEigen(in Matrix, epsilon float64) (EigenFactors, error) {
+	m, n := in.Dims()
 	if m != n {
-		panic(errSquare)
+		return EigenFactors{}, shape_error("Eigen", ErrSquare, in)
 	}
+	a := as_dense(in)
 
 	var v *Dense
 	d := make([]float64, n)
 	e := make([]float64, n)
 
-	if symmetric(a) {
+	_, sym := in.(*SymDense)
+	sym = sym || symmetric(a)
+	if sym {
 		// Tridiagonalize.
 		v = tred2(a, d, e)
 
@@ -63,7 +80,7 @@ func Eigen(a *Dense, epsilon float64) EigenFactors {
 		hqr2(d, e, hess, v, epsilon)
 	}
 
-	return EigenFactors{v, d, e}
+	return EigenFactors{v, d, e, sym}, nil
 }
 
 // Symmetric Householder reduction to tridiagonal form.
@@ -809,7 +826,7 @@ func (f EigenFactors) D() *Dense {
 	d, e := f.d, f.e
 	var n int
 	if n = len(d); n != len(e) {
-		panic(errSquare)
+		panic(ErrSquare)
 	}
 	dm := NewDense(n, n)
 	for i := 0; i < n; i++ {
@@ -822,3 +839,50 @@ func (f EigenFactors) D() *Dense {
 	}
 	return dm
 }
+
+// Symmetric returns whether the input was treated as symmetric, in which
+// case the eigenvalues are real and V is orthogonal.
+func (f EigenFactors) Symmetric() bool {
+	return f.sym
+}
+
+// Values returns the eigenvalues, in the order of the cols of V.
+// Complex eigenvalues come in conjugate pairs, the one with the
+// positive imaginary part first.
+func (f EigenFactors) Values() []complex128 {
+	vals := make([]complex128, len(f.d))
+	for i, v := range f.d {
+		vals[i] = complex(v, f.e[i])
+	}
+	return vals
+}
+
+// Vectors returns the eigenvectors, where vecs[j] is the one for
+// Values()[j]. A real eigenvalue has col j of V as its eigenvector;
+// a complex pair at j and j+1 has the eigenvectors V[:,j] + i*V[:,j+1]
+// and its conjugate, as the 2 by 2 block of D encodes.
+func (f EigenFactors) Vectors() [][]complex128 {
+	v := f.V
+	if v == nil {
+		return nil
+	}
+	vecs := make([][]complex128, len(f.d))
+	for j := range vecs {
+		vecs[j] = make([]complex128, v.Rows())
+		switch {
+		case f.e[j] > 0:
+			for i := range vecs[j] {
+				vecs[j][i] = complex(v.Get(i, j), v.Get(i, j+1))
+			}
+		case f.e[j] < 0:
+			for i := range vecs[j] {
+				vecs[j][i] = complex(v.Get(i, j-1), -v.Get(i, j))
+			}
+		default:
+			for i := range vecs[j] {
+				vecs[j][i] = complex(v.Get(i, j), 0)
+			}
+		}
+	}
+	return vecs
+}
@@ -7,6 +7,7 @@ package dense
 import (
 	check "launchpad.net/gocheck"
 	"math"
+	"math/cmplx"
 )
 
 func (s *S) TestEigen(c *check.C) {
@@ -100,3 +101,72 @@ func (s *S) TestEigen(c *check.C) {
 		c.Check(Approx(t.a, ef.V, 1e-12), check.Equals, true)
 	}
 }
+
+func (s *S) TestEigenComplex(c *check.C) {
+	for _, t := range []struct {
+		a    *Dense
+		sym  bool
+		vals []complex128
+	}{
+		{
+			a: make_dense(2, 2, []float64{
+				0, -1,
+				1, 0,
+			}),
+			vals: []complex128{1i, -1i},
+		},
+		{
+			a: make_dense(3, 3, []float64{
+				1, -2, 0,
+				2, 1, 0,
+				0, 0, 3,
+			}),
+			vals: []complex128{1 + 2i, 1 - 2i, 3},
+		},
+		{
+			a: make_dense(3, 3, []float64{
+				4, 1, 1,
+				1, 2, 3,
+				1, 3, 6,
+			}),
+			sym: true,
+		},
+		{ // Jama badeigs
+			a: make_dense(5, 5, []float64{
+				0, 0, 0, 0, 0,
+				0, 0, 0, 0, 1,
+				0, 0, 0, 1, 0,
+				1, 1, 0, 0, 1,
+				1, 0, 1, 0, 1,
+			}),
+		},
+	} {
+		ef := Eigen(Clone(t.a), math.Pow(2, -52.0))
+		c.Check(ef.Symmetric(), check.Equals, t.sym)
+		vals, vecs := ef.Values(), ef.Vectors()
+		if t.vals != nil {
+			for i, v := range t.vals {
+				c.Check(cmplx.Abs(vals[i]-v) < 1e-12, check.Equals, true, check.Commentf("%v", vals))
+			}
+		}
+
+		// a * v = lambda * v, for nonzero v.
+		n := t.a.Rows()
+		for j, lambda := range vals {
+			v := vecs[j]
+			var norm float64
+			for i := 0; i < n; i++ {
+				var av complex128
+				for k := 0; k < n; k++ {
+					av += complex(t.a.Get(i, k), 0) * v[k]
+				}
+				c.Check(cmplx.Abs(av-lambda*v[i]) < 1e-10, check.Equals, true)
+				norm += cmplx.Abs(v[i])
+			}
+			c.Check(norm > 0, check.Equals, true)
+			if t.sym {
+				c.Check(imag(lambda), check.Equals, 0.0)
+			}
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package dense
+
+import (
+	"math"
+	"math/rand"
+)
+
+// EigenSym computes eigenvalues and, if vectors is set, eigenvectors of
+// the symmetric matrix a, which is only read, in its lower triangle.
+// The eigenvalues are numbered in ascending order, or in descending order
+// if desc is set, and only those with indices lo <= k < hi are computed.
+// Thus
+//    EigenSym(a, 0, k, true, true)
+// gives the k largest eigenpairs, e.g. the leading principal components
+// of a covariance matrix, and EigenSym(a, 0, n, false, true) gives all
+// of them in ascending order.
+//
+// The result has the k = hi - lo eigenvalues in D, in the chosen order,
+// and the eigenvectors in the cols of the n by k matrix V, which is nil
+// if vectors is not set. EigenSym panics with ErrIndexOutOfRange if the
+// indices are not in 0 <= lo <= hi <= n; TryEigenSym returns the error
+// instead.
+//
+// a is reduced to a tridiagonal T = Q' * a * Q by Householder
+// reflections, which takes 4/3 n^3 flops however few eigenpairs are
+// wanted. If all eigenvectors are wanted, Q is formed and T is
+// diagonalized by QL as in Eigen. Otherwise the eigenvalues of T are
+// found by bisection, in O(n) work for each of some 50 steps per
+// eigenvalue, and its eigenvectors by inverse iteration, as in LAPACK
+// DSTEBZ and DSTEIN; they are mapped back by the reflections in
+// O(n^2 * k) work, without forming Q. This saves the forming of Q and
+// the O(n^3) QL iteration, the bulk of the work of Eigen: for n = 500,
+// the 5 largest eigenpairs take about a tenth of the time of Eigen.
+func EigenSym(a Matrix, lo, hi int, desc, vectors bool) EigenFactors {
+	f, e := TryEigenSym(a, lo, hi, desc, vectors)
+	if e != nil {
+		panic(e)
+	}
+	return f
+}
+
+// TryEigenSym is EigenSym returning an error instead of panicking.
+func TryEigenSym(a Matrix, lo, hi int, desc, vectors bool) (EigenFactors, error) {
+	n, c := a.Dims()
+	if c != n {
+		return EigenFactors{}, shape_error("EigenSym", ErrSquare, a)
+	}
+	if lo < 0 || lo > hi || hi > n {
+		return EigenFactors{}, ErrIndexOutOfRange
+	}
+	if desc {
+		lo, hi = n-hi, n-lo
+	}
+	h, d, e, tau := sym_tridiag(a)
+	return eigen_sym(h, d, e, tau, lo, hi, desc, vectors), nil
+}
+
+// EigenSymInterval is EigenSym computing the eigenpairs with eigenvalues
+// in the half-open interval vl <= lambda < vu, as counted by Sturm
+// sequences; their number is found in O(n) work before any is computed.
+// An eigenvalue that is exactly vl, e.g. the zero eigenvalue of a graph
+// Laplacian with vl = 0, is included. (LAPACK DSTEBZ takes vl < lambda
+// <= vu instead.)
+func EigenSymInterval(a Matrix, vl, vu float64, desc, vectors bool) EigenFactors {
+	f, e := TryEigenSymInterval(a, vl, vu, desc, vectors)
+	if e != nil {
+		panic(e)
+	}
+	return f
+}
+
+// TryEigenSymInterval is EigenSymInterval returning an error instead of
+// panicking.
+func TryEigenSymInterval(a Matrix, vl, vu float64, desc, vectors bool) (EigenFactors, error) {
+	n, c := a.Dims()
+	if c != n {
+		return EigenFactors{}, shape_error("EigenSymInterval", ErrSquare, a)
+	}
+	h, d, e, tau := sym_tridiag(a)
+	_, _, pivmin := tri_bounds(d, e)
+	lo, hi := sturm_count(d, e, vl, pivmin), sturm_count(d, e, vu, pivmin)
+	if hi < lo {
+		hi = lo
+	}
+	return eigen_sym(h, d, e, tau, lo, hi, desc, vectors), nil
+}
+
+// sym_tridiag reduces the symmetric matrix a, read in its lower
+// triangle, to the tridiagonal T = Q' * a * Q, with diagonal d and
+// subdiagonal e[1:], and e[0] = 0, by Householder reflections as in
+// LAPACK DSYTD2. Q = H_0 * H_1 * ... * H_{n-3} is not formed: the
+// reflection H_k = I - tau[k] * v * v' acts on elements k+1 and on, and
+// has v[k+1] = 1 and v[k+2:] in row k of h, above the diagonal.
+func sym_tridiag(a Matrix) (h *Dense, d, e, tau []float64) {
+	n, _ := a.Dims()
+	h = NewDense(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			h.Set(i, j, a.At(i, j))
+		}
+	}
+	d = make([]float64, n)
+	e = make([]float64, n)
+	tau = make([]float64, n)
+	w := make([]float64, n)
+	for k := 0; k+1 < n; k++ {
+		d[k] = h.Get(k, k)
+
+		// The reflection that zeros col k below the subdiagonal.
+		alpha := h.Get(k+1, k)
+		var xnorm float64
+		for i := k + 2; i < n; i++ {
+			xnorm = math.Hypot(xnorm, h.Get(i, k))
+		}
+		if xnorm == 0 {
+			e[k+1] = alpha
+			continue
+		}
+		beta := -math.Copysign(math.Hypot(alpha, xnorm), alpha)
+		tau[k] = (beta - alpha) / beta
+		e[k+1] = beta
+		v := h.RowView(k)[k+1:]
+		v[0] = 1
+		for i := k + 2; i < n; i++ {
+			v[i-k-1] = h.Get(i, k) / (alpha - beta)
+		}
+
+		// The trailing matrix b, in the lower triangle of
+		// h[k+1:, k+1:], becomes H_k * b * H_k = b - v * w' - w * v',
+		// with w = p - tau/2 * (p' * v) * v and p = tau * b * v.
+		m := len(v)
+		p := w[:m]
+		for i := range p {
+			p[i] = 0
+		}
+		for i := 0; i < m; i++ {
+			row := h.RowView(k + 1 + i)[k+1 : k+2+i]
+			p[i] += dot(row, v[:i+1])
+			for j, x := range row[:i] {
+				p[j] += x * v[i]
+			}
+		}
+		scale(p, tau[k], p)
+		c := -tau[k] / 2 * dot(p, v)
+		for i, x := range v {
+			p[i] += c * x
+		}
+		for i := 0; i < m; i++ {
+			row := h.RowView(k + 1 + i)[k+1 : k+2+i]
+			for j := range row {
+				row[j] -= v[i]*p[j] + p[i]*v[j]
+			}
+		}
+	}
+	if n > 0 {
+		d[n-1] = h.Get(n-1, n-1)
+	}
+	return h, d, e, tau
+}
+
+// tri_apply_q overwrites x with Q * x, for the Q of sym_tridiag in
+// h and tau.
+func tri_apply_q(h *Dense, tau, x []float64) {
+	for k := len(x) - 2; k >= 0; k-- {
+		if tau[k] == 0 {
+			continue
+		}
+		v := h.RowView(k)[k+1:]
+		y := x[k+1:]
+		s := tau[k] * dot(v, y)
+		for i, vi := range v {
+			y[i] -= s * vi
+		}
+	}
+}
+
+// eigen_sym returns the eigenpairs with ascending indices lo <= k < hi
+// of Q * T * Q', for the Q and T of sym_tridiag, in the order given by
+// desc.
+func eigen_sym(h *Dense, d, e, tau []float64, lo, hi int, desc, vectors bool) EigenFactors {
+	n, k := len(d), hi-lo
+	var (
+		w []float64
+		v *Dense
+	)
+	switch {
+	case k == 0:
+		if vectors {
+			v = NewDense(n, 0)
+		}
+	case k == n && vectors:
+		// Row i of v is Q * e_i, so v is Q', and then Q.
+		v = eye(n)
+		for i := 0; i < n; i++ {
+			tri_apply_q(h, tau, v.RowView(i))
+		}
+		v.T()
+		tql2(d, e, v, 2.2204e-16)
+		w = d
+	default:
+		w = tri_bisect(d, e, lo, hi)
+		if vectors {
+			z := tri_invit(d, e, w)
+			for i := 0; i < k; i++ {
+				tri_apply_q(h, tau, z.RowView(i))
+			}
+			v = T(z, nil)
+		}
+	}
+
+	if desc {
+		for i, j := 0, k-1; i < j; i, j = i+1, j-1 {
+			w[i], w[j] = w[j], w[i]
+			if v != nil {
+				for r := 0; r < n; r++ {
+					row := v.RowView(r)
+					row[i], row[j] = row[j], row[i]
+				}
+			}
+		}
+	}
+	if w == nil {
+		w = []float64{}
+	}
+	return EigenFactors{v, w, make([]float64, k), true}
+}
+
+// The smallest normal float64.
+const safmin = 2.2250738585072014e-308
+
+// tri_bounds returns the Gershgorin bounds gl and gu, widened for
+// rounding, of the eigenvalues of the symmetric tridiagonal matrix with
+// diagonal d and subdiagonal e[1:], and the smallest pivot magnitude
+// pivmin allowed in sturm_count.
+func tri_bounds(d, e []float64) (gl, gu, pivmin float64) {
+	n := len(d)
+	gl, gu = math.Inf(1), math.Inf(-1)
+	emax2 := 1.0
+	for i, v := range d {
+		r := math.Abs(e[i])
+		if i+1 < n {
+			r += math.Abs(e[i+1])
+		}
+		gl = math.Min(gl, v-r)
+		gu = math.Max(gu, v+r)
+		emax2 = math.Max(emax2, e[i]*e[i])
+	}
+	pivmin = safmin * emax2
+	fudge := 2*2.2204e-16*float64(n)*math.Max(math.Abs(gl), math.Abs(gu)) + 2*pivmin
+	return gl - fudge, gu + fudge, pivmin
+}
+
+// sturm_count returns the number of eigenvalues less than x of the
+// symmetric tridiagonal matrix with diagonal d and subdiagonal e[1:],
+// where e[0] = 0, by the signs of the pivots of T - x * I. Pivots
+// smaller than pivmin in magnitude are replaced by pivmin, as if x were
+// slightly less, so that an eigenvalue equal to x is not counted.
+// (LAPACK DSTEBZ uses -pivmin, and counts the eigenvalues not greater
+// than x.)
+func sturm_count(d, e []float64, x, pivmin float64) int {
+	var cnt int
+	q := 1.0
+	for i, v := range d {
+		q = v - x - e[i]*e[i]/q
+		if math.Abs(q) < pivmin {
+			q = pivmin
+		}
+		if q < 0 {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// tri_bisect returns the eigenvalues with ascending indices lo <= k < hi
+// of the symmetric tridiagonal matrix with diagonal d and subdiagonal
+// e[1:], by bisection to an absolute accuracy of eps * |T|.
+func tri_bisect(d, e []float64, lo, hi int) []float64 {
+	const eps = 2.2204e-16
+	gl, gu, pivmin := tri_bounds(d, e)
+	atol := math.Max(eps*math.Max(math.Abs(gl), math.Abs(gu)), pivmin)
+
+	w := make([]float64, hi-lo)
+	left := gl
+	for k := lo; k < hi; k++ {
+		// The k-th eigenvalue stays in [l, u).
+		l, u := left, gu
+		for u-l > math.Max(atol, 2*eps*math.Max(math.Abs(l), math.Abs(u))) {
+			mid := l + (u-l)/2
+			if sturm_count(d, e, mid, pivmin) > k {
+				u = mid
+			} else {
+				l = mid
+			}
+		}
+		w[k-lo] = l + (u-l)/2
+		left = l
+	}
+	return w
+}
+
+// tri_lu is the factorization P * (T - shift * I) = L * U of a symmetric
+// tridiagonal T by Gaussian elimination with partial pivoting, as in
+// LAPACK DLAGTF, where U has two superdiagonals.
+type tri_lu struct {
+	u0, u1, u2 []float64 // diagonals of U
+	l          []float64 // multipliers of L
+	swap       []bool    // whether rows i and i+1 were exchanged at step i
+}
+
+// new_tri_lu factors T - shift * I for T with diagonal d and subdiagonal
+// e[1:]. Pivots smaller than tiny in magnitude are replaced by +-tiny, so
+// that the factorization at an eigenvalue can be used for inverse
+// iteration.
+func new_tri_lu(d, e []float64, shift, tiny float64) *tri_lu {
+	n := len(d)
+	f := &tri_lu{
+		make([]float64, n), make([]float64, n), make([]float64, n),
+		make([]float64, n), make([]bool, n),
+	}
+	u0, u1, u2 := f.u0, f.u1, f.u2
+	for i, v := range d {
+		u0[i] = v - shift
+		if i+1 < n {
+			u1[i] = e[i+1]
+		}
+	}
+	for i := 0; i+1 < n; i++ {
+		c := e[i+1] // the element of row i+1 in col i
+		if math.Abs(c) > math.Abs(u0[i]) {
+			// Row i+1 is the pivot row: c, u0[i+1], u1[i+1].
+			f.swap[i] = true
+			f.l[i] = u0[i] / c
+			r1, r2 := u0[i+1], u1[i+1]
+			o1 := u1[i]
+			u0[i], u1[i], u2[i] = c, r1, r2
+			u0[i+1] = o1 - f.l[i]*r1
+			u1[i+1] = -f.l[i] * r2
+			continue
+		}
+		if math.Abs(u0[i]) < tiny {
+			u0[i] = math.Copysign(tiny, u0[i])
+		}
+		f.l[i] = c / u0[i]
+		u0[i+1] -= f.l[i] * u1[i]
+	}
+	if math.Abs(u0[n-1]) < tiny {
+		u0[n-1] = math.Copysign(tiny, u0[n-1])
+	}
+	return f
+}
+
+// solve overwrites x with (T - shift * I)^-1 * x.
+func (f *tri_lu) solve(x []float64) {
+	n := len(x)
+	for i := 0; i+1 < n; i++ {
+		if f.swap[i] {
+			x[i], x[i+1] = x[i+1], x[i]
+		}
+		x[i+1] -= f.l[i] * x[i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		v := x[i]
+		if i+1 < n {
+			v -= f.u1[i] * x[i+1]
+		}
+		if i+2 < n {
+			v -= f.u2[i] * x[i+2]
+		}
+		x[i] = v / f.u0[i]
+	}
+}
+
+// tri_invit returns in its rows the eigenvectors of the symmetric
+// tridiagonal matrix with diagonal d and subdiagonal e[1:] for the
+// ascending eigenvalues w, by inverse iteration from random starting
+// vectors, as in LAPACK DSTEIN. Equal eigenvalues are separated slightly,
+// and the vectors of a cluster of eigenvalues closer than 1e-3 * |T| are
+// orthogonalized against each other.
+func tri_invit(d, e, w []float64) *Dense {
+	const (
+		eps   = 2.2204e-16
+		iters = 5
+	)
+	n := len(d)
+	var tnorm float64
+	for i, v := range d {
+		r := math.Abs(v) + math.Abs(e[i])
+		if i+1 < n {
+			r += math.Abs(e[i+1])
+		}
+		tnorm = math.Max(tnorm, r)
+	}
+	tiny := math.Max(eps*tnorm, safmin)
+	ortol := 1e-3 * tnorm
+	pertol := 10 * eps * tnorm
+
+	z := NewDense(len(w), n)
+	rnd := rand.New(rand.NewSource(1))
+	var first int // first vector of the current cluster
+	var shift float64
+	for j, lambda := range w {
+		if j > 0 && lambda-w[j-1] > ortol {
+			first = j
+		}
+		if j > 0 && lambda-shift < pertol {
+			lambda = shift + pertol
+		}
+		shift = lambda
+
+		lu := new_tri_lu(d, e, lambda, tiny)
+		x := z.RowView(j)
+		for i := range x {
+			x[i] = 2*rnd.Float64() - 1
+		}
+		for it := 0; it < iters; it++ {
+			lu.solve(x)
+			for p := first; p < j; p++ {
+				y := z.RowView(p)
+				s := dot(x, y)
+				for i, v := range y {
+					x[i] -= s * v
+				}
+			}
+			var norm float64
+			for _, v := range x {
+				norm = math.Hypot(norm, v)
+			}
+			scale(x, 1/norm, x)
+		}
+	}
+	return z
+}
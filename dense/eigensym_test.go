@@ -0,0 +1,189 @@
+package dense
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	check "launchpad.net/gocheck"
+)
+
+// rand_sym returns a random n by n symmetric matrix.
+func rand_sym(n int) *Dense {
+	a, _ := randDense(n, 1, rand.NormFloat64)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			a.Set(j, i, a.Get(i, j))
+		}
+	}
+	return a
+}
+
+// check_eigen_sym checks that f holds eigenpairs of a, with orthonormal
+// eigenvectors, and the eigenvalues want.
+func check_eigen_sym(c *check.C, a *Dense, f EigenFactors, want []float64, comment check.CommentInterface) {
+	k := len(want)
+	c.Check(f.Symmetric(), check.Equals, true, comment)
+	c.Assert(len(f.Values()), check.Equals, k, comment)
+	for i, v := range f.Values() {
+		c.Check(math.Abs(real(v)-want[i]) < 1e-10, check.Equals, true, comment)
+	}
+	if f.V == nil {
+		return
+	}
+	n, vk := f.V.Dims()
+	c.Assert(n, check.Equals, a.Rows(), comment)
+	c.Assert(vk, check.Equals, k, comment)
+	if k == 0 {
+		return
+	}
+	c.Check(Approx(Mult(a, f.V, nil), Mult(f.V, f.D(), nil), 1e-9), check.Equals, true, comment)
+	c.Check(Approx(Mult(f.V.TView(), f.V, nil), eye(k), 1e-10), check.Equals, true, comment)
+}
+
+func (s *S) TestEigenSym(c *check.C) {
+	for _, n := range []int{1, 2, 5, 30} {
+		a := rand_sym(n)
+		all := Eigen(Clone(a), 2.2204e-16).d // ascending
+		desc := make([]float64, n)
+		for i, v := range all {
+			desc[n-1-i] = v
+		}
+
+		for _, t := range []struct {
+			lo, hi int
+		}{
+			{0, n}, {0, 1}, {n - 1, n}, {0, n / 2}, {n / 3, n - n/3}, {n / 2, n / 2},
+		} {
+			for _, vectors := range []bool{true, false} {
+				comment := check.Commentf("n %d, lo %d, hi %d, vectors %v", n, t.lo, t.hi, vectors)
+				f := EigenSym(a, t.lo, t.hi, false, vectors)
+				check_eigen_sym(c, a, f, all[t.lo:t.hi], comment)
+				c.Check(f.V == nil, check.Equals, !vectors)
+				f = EigenSym(a, t.lo, t.hi, true, vectors)
+				check_eigen_sym(c, a, f, desc[t.lo:t.hi], comment)
+			}
+		}
+	}
+
+	// Only the lower triangle is read, and a is left unchanged.
+	a := rand_sym(6)
+	b := Clone(a)
+	for i := 0; i < 6; i++ {
+		for j := i + 1; j < 6; j++ {
+			b.Set(i, j, 100)
+		}
+	}
+	want := EigenSym(a, 0, 3, true, true)
+	got := EigenSym(b, 0, 3, true, true)
+	c.Check(got.Values(), check.DeepEquals, want.Values())
+	c.Check(b.Get(0, 5), check.Equals, 100.0)
+
+	_, e := TryEigenSym(a, 2, 7, false, true)
+	c.Check(e, check.Equals, ErrIndexOutOfRange)
+	_, e = TryEigenSym(a, 3, 2, false, true)
+	c.Check(e, check.Equals, ErrIndexOutOfRange)
+	_, e = TryEigenSym(NewDense(2, 3), 0, 1, false, true)
+	c.Check(errors.Is(e, ErrSquare), check.Equals, true)
+}
+
+func (s *S) TestEigenSymClustered(c *check.C) {
+	// Repeated eigenvalues: a = q * diag(w) * q' with orthogonal q.
+	w := []float64{-2, 1, 1, 1, 1e-9, 3, 3, 3 + 1e-12, 5}
+	n := len(w)
+	q := QR(rand_sym(n)).Q()
+	a := Mult(Mult(q, make_diag(w), nil), q.TView(), nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			a.Set(j, i, a.Get(i, j))
+		}
+	}
+	sorted := []float64{-2, 1e-9, 1, 1, 1, 3, 3, 3 + 1e-12, 5}
+	for _, t := range []struct{ lo, hi int }{{0, n}, {1, 8}, {2, 5}, {5, 8}} {
+		f := EigenSym(a, t.lo, t.hi, false, true)
+		check_eigen_sym(c, a, f, sorted[t.lo:t.hi], check.Commentf("lo %d, hi %d", t.lo, t.hi))
+	}
+
+	// The identity and the zero matrix.
+	check_eigen_sym(c, eye(5), EigenSym(eye(5), 1, 4, false, true), []float64{1, 1, 1}, check.Commentf("identity"))
+	check_eigen_sym(c, NewDense(4, 4), EigenSym(NewDense(4, 4), 0, 2, true, true), []float64{0, 0}, check.Commentf("zero"))
+}
+
+func (s *S) TestEigenSymInterval(c *check.C) {
+	a := rand_sym(20)
+	all := Eigen(Clone(a), 2.2204e-16).d
+
+	for _, t := range []struct {
+		vl, vu float64
+	}{
+		{math.Inf(-1), math.Inf(1)},
+		{0, math.Inf(1)},
+		{-1, 1},
+		{(all[2] + all[3]) / 2, (all[6] + all[7]) / 2},
+		{all[19] + 1, all[19] + 2},
+		{1, -1},
+	} {
+		var want []float64
+		for _, v := range all {
+			if t.vl <= v && v < t.vu {
+				want = append(want, v)
+			}
+		}
+		comment := check.Commentf("[%v, %v)", t.vl, t.vu)
+		f := EigenSymInterval(a, t.vl, t.vu, false, true)
+		check_eigen_sym(c, a, f, want, comment)
+		f = EigenSymInterval(a, t.vl, t.vu, true, false)
+		c.Assert(len(f.Values()), check.Equals, len(want), comment)
+		for i, v := range f.Values() {
+			c.Check(math.Abs(real(v)-want[len(want)-1-i]) < 1e-10, check.Equals, true, comment)
+		}
+	}
+}
+
+func (s *S) TestEigenSymIntervalBoundary(c *check.C) {
+	// Eigenvalues exactly on the bounds: the interval is [vl, vu).
+	path := make_dense(3, 3, []float64{ // a graph Laplacian
+		1, -1, 0,
+		-1, 2, -1,
+		0, -1, 1,
+	})
+	for _, t := range []struct {
+		a      *Dense
+		vl, vu float64
+		want   []float64
+	}{
+		{eye(4), 1, 2, []float64{1, 1, 1, 1}},
+		{eye(4), 0, 1, nil},
+		{make_diag([]float64{0, 2, 3}), 0, 2.5, []float64{0, 2}},
+		{make_diag([]float64{0, 2, 3}), 2, 3, []float64{2}},
+		{make_diag([]float64{0, 2, 3}), -1, 0, nil},
+		{make_dense(2, 2, []float64{2, 1, 1, 2}), 1, 3, []float64{1}},
+		{path, 0, 1, []float64{0}},
+		{path, 0, 3, []float64{0, 1}},
+		{path, 1, 4, []float64{1, 3}},
+	} {
+		comment := check.Commentf("%v in [%v, %v)", t.a, t.vl, t.vu)
+		f := EigenSymInterval(t.a, t.vl, t.vu, false, true)
+		check_eigen_sym(c, t.a, f, t.want, comment)
+	}
+}
+
+func make_diag(d []float64) *Dense {
+	m := NewDense(len(d), len(d))
+	for i, v := range d {
+		m.Set(i, i, v)
+	}
+	return m
+}
+
+func BenchmarkEigenSymAll200(b *testing.B)   { eigenSymBench(b, 200, 200) }
+func BenchmarkEigenSymLargest5(b *testing.B) { eigenSymBench(b, 200, 5) }
+
+func eigenSymBench(b *testing.B, n, k int) {
+	a := rand_sym(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EigenSym(a, 0, k, true, true)
+	}
+}
@@ -0,0 +1,157 @@
+package dense
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Matrices with more than printThreshold elements are printed
+// in summary, showing only the first and last printEdge rows and cols
+// of each dimension that is longer than 2*printEdge.
+// These are the defaults of NumPy.
+const (
+	printThreshold = 1000
+	printEdge      = 3
+)
+
+// Format implements fmt.Formatter.
+//
+// The verbs %v, %e, %E, %f, %F, %g and %G print m as an aligned grid
+// of rows, such as
+//    [[  1 2.5]
+//     [3.5  -4]]
+// with each element formatted according to the verb, precision and
+// '+' flag; %v formats elements as %g does.
+// Large matrices are printed with the middle rows and cols elided.
+//
+// %#v prints Go source that reconstructs m by DenseView.
+func (m *Dense) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('#') {
+			m.format_go(f)
+			return
+		}
+		m.format_grid(f, 'g')
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		m.format_grid(f, verb)
+	default:
+		fmt.Fprintf(f, "%%!%c(*dense.Dense=%dx%d)", verb, m.rows, m.cols)
+	}
+}
+
+// shown returns the indices of a dimension of length n that are
+// printed, with -1 standing for the elided part.
+func shown(n int, elide bool) []int {
+	var idx []int
+	if elide && n > 2*printEdge {
+		for i := 0; i < printEdge; i++ {
+			idx = append(idx, i)
+		}
+		idx = append(idx, -1)
+		for i := n - printEdge; i < n; i++ {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+	for i := 0; i < n; i++ {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+func (m *Dense) format_grid(f fmt.State, verb rune) {
+	if m.rows == 0 {
+		f.Write([]byte("[]"))
+		return
+	}
+
+	prec, ok := f.Precision()
+	if !ok {
+		prec = 6
+		if verb == 'g' || verb == 'G' {
+			prec = -1
+		}
+	}
+	if verb == 'F' {
+		verb = 'f'
+	}
+	plus := f.Flag('+')
+
+	elide := m.rows*m.cols > printThreshold
+	rows := shown(m.rows, elide)
+	cols := shown(m.cols, elide)
+
+	// Format the elements to print, and find the widest.
+	cells := make([][]string, len(rows))
+	width := 0
+	for i, r := range rows {
+		if r < 0 {
+			continue
+		}
+		cells[i] = make([]string, len(cols))
+		for j, c := range cols {
+			if c < 0 {
+				cells[i][j] = "..."
+				continue
+			}
+			v := m.Get(r, c)
+			s := strconv.FormatFloat(v, byte(verb), prec, 64)
+			if plus && !math.Signbit(v) {
+				s = "+" + s
+			}
+			cells[i][j] = s
+			if len(s) > width {
+				width = len(s)
+			}
+		}
+	}
+
+	for i, row := range cells {
+		if i == 0 {
+			f.Write([]byte("["))
+		} else {
+			f.Write([]byte("\n "))
+		}
+		if row == nil {
+			f.Write([]byte("..."))
+			continue
+		}
+		f.Write([]byte("["))
+		for j, s := range row {
+			if j > 0 {
+				f.Write([]byte(" "))
+			}
+			if s != "..." {
+				s = strings.Repeat(" ", width-len(s)) + s
+			}
+			f.Write([]byte(s))
+		}
+		f.Write([]byte("]"))
+	}
+	f.Write([]byte("]"))
+}
+
+func (m *Dense) format_go(f fmt.State) {
+	fmt.Fprint(f, "dense.DenseView([]float64{")
+	for i := 0; i < m.rows; i++ {
+		for j, v := range m.RowView(i) {
+			if i > 0 || j > 0 {
+				fmt.Fprint(f, ", ")
+			}
+			switch {
+			case math.IsNaN(v):
+				fmt.Fprint(f, "math.NaN()")
+			case math.IsInf(v, 1):
+				fmt.Fprint(f, "math.Inf(1)")
+			case math.IsInf(v, -1):
+				fmt.Fprint(f, "math.Inf(-1)")
+			default:
+				fmt.Fprint(f, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+	}
+	fmt.Fprintf(f, "}, %d, %d)", m.rows, m.cols)
+}
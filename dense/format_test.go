@@ -0,0 +1,52 @@
+package dense
+
+import (
+	"fmt"
+	check "launchpad.net/gocheck"
+	"math"
+)
+
+func (s *S) TestFormat(c *check.C) {
+	m := flatten2dense([][]float64{
+		{1, 2.5},
+		{3.5, -4},
+	})
+	for _, test := range []struct {
+		format, want string
+	}{
+		{"%v", "[[  1 2.5]\n [3.5  -4]]"},
+		{"%.3f", "[[ 1.000  2.500]\n [ 3.500 -4.000]]"},
+		{"%+.1f", "[[+1.0 +2.5]\n [+3.5 -4.0]]"},
+		{"%.2e", "[[ 1.00e+00  2.50e+00]\n [ 3.50e+00 -4.00e+00]]"},
+		{"%#v", "dense.DenseView([]float64{1, 2.5, 3.5, -4}, 2, 2)"},
+		{"%d", "%!d(*dense.Dense=2x2)"},
+	} {
+		c.Check(fmt.Sprintf(test.format, m), check.Equals, test.want)
+	}
+
+	c.Check(fmt.Sprint(NewDense(0, 0)), check.Equals, "[]")
+	c.Check(fmt.Sprint(m.SubmatrixView(1, 0, 1, 2)), check.Equals, "[[3.5  -4]]")
+	c.Check(fmt.Sprintf("%#v", make_dense(1, 3, []float64{math.NaN(), math.Inf(1), math.Inf(-1)})),
+		check.Equals, "dense.DenseView([]float64{math.NaN(), math.Inf(1), math.Inf(-1)}, 1, 3)")
+}
+
+func (s *S) TestFormatElided(c *check.C) {
+	m := NewDense(40, 30)
+	m.Apply(func(r, c int, v float64) float64 { return float64(100*r + c) })
+	c.Check(fmt.Sprint(m), check.Equals,
+		"[[   0    1    2 ...   27   28   29]\n"+
+			" [ 100  101  102 ...  127  128  129]\n"+
+			" [ 200  201  202 ...  227  228  229]\n"+
+			" ...\n"+
+			" [3700 3701 3702 ... 3727 3728 3729]\n"+
+			" [3800 3801 3802 ... 3827 3828 3829]\n"+
+			" [3900 3901 3902 ... 3927 3928 3929]]")
+
+	// Only long dimensions are elided.
+	m = NewDense(2, 600)
+	out := fmt.Sprint(m)
+	c.Check(out, check.Equals, "[[0 0 0 ... 0 0 0]\n [0 0 0 ... 0 0 0]]")
+
+	// %#v is never elided.
+	c.Check(len(fmt.Sprintf("%#v", m)) > 2400, check.Equals, true)
+}
@@ -0,0 +1,312 @@
+package dense
+
+import (
+	"math"
+
+	"github.com/gonum/blas"
+)
+
+// LDLFactors contains the factorization
+//    P * M * P' = L * D * L'
+// of a symmetric, possibly indefinite, matrix M, where L is unit lower
+// triangular, D is block diagonal with 1 by 1 and 2 by 2 blocks, and P
+// is the permutation chosen by Bunch-Kaufman pivoting.
+// It has the Solve, Inv and Det methods of CholFactors, which it
+// replaces when M is not positive definite, e.g. a saddle-point matrix.
+type LDLFactors struct {
+	l    *Dense
+	d    []float64 // diagonal of D
+	e    []float64 // e[k] is D[k+1][k], nonzero for a 2 by 2 block at k
+	perm []int     // row k of P * M is row perm[k] of M
+}
+
+// LDL returns the LDL' decomposition of the matrix M.
+// M is only read, in its lower triangle, so it may be any Matrix,
+// e.g. a Transpose view or a *SymDense.
+// The success flag is false if M is not square.
+func LDL(M Matrix) (*LDLFactors, bool) {
+	f := &LDLFactors{}
+	b := f.LDL(M)
+	return f, b
+}
+
+// LDL conducts LDL' decomposition for the matrix M.
+// The receiver f is updated. Success flag is returned.
+//
+// The factorization always exists, even for a singular M, in which
+// case D is singular; Solve then fails.
+//
+// This is the Bunch-Kaufman algorithm, as in LAPACK DSYTF2: at each
+// step, a 1 by 1 or a 2 by 2 pivot is chosen by comparing the diagonal
+// to the largest elements in its col, which bounds the growth of the
+// elements of L.
+func (f *LDLFactors) LDL(M Matrix) bool {
+	n, c := M.Dims()
+	if c != n {
+		f.l = nil
+		return false
+	}
+
+	// The symmetric trailing matrix, updated in full.
+	a := NewDense(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			v := M.At(i, j)
+			a.Set(i, j, v)
+			a.Set(j, i, v)
+		}
+	}
+	l := NewDense(n, n)
+	d := make([]float64, n)
+	e := make([]float64, n)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	// swap exchanges i and j, both >= k, in the trailing matrix,
+	// and in the cols of L found so far.
+	swap_sym := func(k, i, j int) {
+		if i == j {
+			return
+		}
+		swap(a.RowView(i), a.RowView(j))
+		for r := 0; r < n; r++ {
+			row := a.RowView(r)
+			row[i], row[j] = row[j], row[i]
+		}
+		swap(l.RowView(i)[:k], l.RowView(j)[:k])
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+
+	alpha := (1 + math.Sqrt(17)) / 8
+	for k := 0; k < n; {
+		// The largest element below the diagonal in col k.
+		colmax, r := 0.0, k
+		for i := k + 1; i < n; i++ {
+			if v := math.Abs(a.Get(i, k)); v > colmax {
+				colmax, r = v, i
+			}
+		}
+		absakk := math.Abs(a.Get(k, k))
+
+		size := 1
+		switch {
+		case absakk >= alpha*colmax:
+			// Also when the col is zero.
+		default:
+			// The largest element off the diagonal in row r.
+			rowmax := 0.0
+			for j := k; j < n; j++ {
+				if j != r {
+					rowmax = math.Max(rowmax, math.Abs(a.Get(r, j)))
+				}
+			}
+			switch {
+			case absakk*rowmax >= alpha*colmax*colmax:
+			case math.Abs(a.Get(r, r)) >= alpha*rowmax:
+				swap_sym(k, k, r)
+			default:
+				swap_sym(k, k+1, r)
+				size = 2
+			}
+		}
+
+		l.Set(k, k, 1)
+		if size == 1 {
+			dk := a.Get(k, k)
+			d[k] = dk
+			if dk != 0 {
+				for i := k + 1; i < n; i++ {
+					l.Set(i, k, a.Get(i, k)/dk)
+				}
+				for i := k + 1; i < n; i++ {
+					lik := l.Get(i, k)
+					aRowi := a.RowView(i)
+					for j := k + 1; j < n; j++ {
+						aRowi[j] -= lik * a.Get(j, k)
+					}
+				}
+			}
+			k++
+			continue
+		}
+
+		// A 2 by 2 pivot [p q; q s], which is nonsingular.
+		p, q, s := a.Get(k, k), a.Get(k+1, k), a.Get(k+1, k+1)
+		det := p*s - q*q
+		d[k], d[k+1], e[k] = p, s, q
+		l.Set(k+1, k+1, 1)
+		for i := k + 2; i < n; i++ {
+			u, v := a.Get(i, k), a.Get(i, k+1)
+			l.Set(i, k, (s*u-q*v)/det)
+			l.Set(i, k+1, (p*v-q*u)/det)
+		}
+		for i := k + 2; i < n; i++ {
+			li0, li1 := l.Get(i, k), l.Get(i, k+1)
+			aRowi := a.RowView(i)
+			for j := k + 2; j < n; j++ {
+				aRowi[j] -= li0*a.Get(j, k) + li1*a.Get(j, k+1)
+			}
+		}
+		k += 2
+	}
+
+	f.l, f.d, f.e, f.perm = l, d, e, perm
+	return true
+}
+
+// L returns the unit lower triangular factor L. Since the returned
+// matrix is a view of internal data of f, one is not expected to make
+// changes to it.
+func (f *LDLFactors) L() *TriDense {
+	if f.l == nil {
+		return nil
+	}
+	return TriDenseView(f.l, blas.Lower, blas.Unit)
+}
+
+// D returns the block diagonal factor D in a new matrix.
+func (f *LDLFactors) D() *SymDense {
+	if f.l == nil {
+		return nil
+	}
+	n := len(f.d)
+	d := NewSymDense(n, blas.Lower, false)
+	for k, v := range f.d {
+		d.Set(k, k, v)
+		if k+1 < n {
+			d.Set(k+1, k, f.e[k])
+		}
+	}
+	return d
+}
+
+// Perm returns the permutation P as a slice p, where row k of P * M
+// is row p[k] of M.
+func (f *LDLFactors) Perm() []int {
+	return append([]int(nil), f.perm...)
+}
+
+// Solve returns a matrix x that solves a * x = b where a is the matrix
+// that produced f by LDL(a).
+// The matrix b must have the same number of rows as a.
+// b is overwritten by the operation and returned containing the
+// solution. Solve panics with ErrSingular if a is singular.
+func (f *LDLFactors) Solve(b *Dense) *Dense {
+	return must_dense(f.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f *LDLFactors) TrySolve(b *Dense) (*Dense, error) {
+	l := f.l
+	if l == nil {
+		return nil, ErrInNil
+	}
+	n := l.Rows()
+	if b.Rows() != n {
+		return nil, shape_error("LDLFactors.Solve", ErrShapes, l, b)
+	}
+	if f.singular() {
+		return nil, ErrSingular
+	}
+
+	// Solve L * D * L' * y = P * b, then x = P' * y.
+	y := NewDense(n, b.Cols())
+	for k, i := range f.perm {
+		y.SetRow(k, b.RowView(i))
+	}
+	SolveTri(f.L(), false, y)
+	for k := 0; k < n; k++ {
+		yk := y.RowView(k)
+		if k+1 == n || f.e[k] == 0 {
+			scale(yk, 1/f.d[k], yk)
+			continue
+		}
+		p, q, s := f.d[k], f.e[k], f.d[k+1]
+		det := p*s - q*q
+		yk1 := y.RowView(k + 1)
+		for j, u := range yk {
+			v := yk1[j]
+			yk[j], yk1[j] = (s*u-q*v)/det, (p*v-q*u)/det
+		}
+		k++
+	}
+	SolveTri(f.L(), true, y)
+	for k, i := range f.perm {
+		b.SetRow(i, y.RowView(k))
+	}
+	return b, nil
+}
+
+// singular returns whether D and hence a is singular.
+// A 2 by 2 block of Bunch-Kaufman pivoting is never singular.
+func (f *LDLFactors) singular() bool {
+	for k, v := range f.d {
+		if v == 0 && f.e[k] == 0 && (k == 0 || f.e[k-1] == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// Inv returns the inverse of the matrix a that produced f by LDL(a).
+func (f *LDLFactors) Inv(out *Dense) *Dense {
+	return must_dense(f.TryInv(out))
+}
+
+// TryInv is Inv returning an error instead of panicking.
+func (f *LDLFactors) TryInv(out *Dense) (*Dense, error) {
+	l := f.l
+	if l == nil {
+		return nil, ErrInNil
+	}
+	n := l.Rows()
+	out, e := try_use_dense("LDLFactors.Inv", out, n, n)
+	if e != nil {
+		return nil, e
+	}
+	out.Fill(0.0)
+	out.FillDiag(1.0)
+	return f.TrySolve(out)
+}
+
+// Det returns the determinant of the matrix a that produced f by LDL(a).
+func (f *LDLFactors) Det() float64 {
+	v := 1.0
+	for k := 0; k < len(f.d); k++ {
+		if f.e[k] == 0 {
+			v *= f.d[k]
+			continue
+		}
+		v *= f.d[k]*f.d[k+1] - f.e[k]*f.e[k]
+		k++
+	}
+	return v
+}
+
+// Inertia returns the numbers of positive, negative and zero eigenvalues
+// of the matrix a that produced f by LDL(a), which by Sylvester's law are
+// those of D. An eigenvalue is counted as zero only if a 1 by 1 block of
+// D is exactly zero.
+func (f *LDLFactors) Inertia() (pos, neg, zero int) {
+	for k := 0; k < len(f.d); k++ {
+		if f.e[k] != 0 {
+			// A 2 by 2 block of Bunch-Kaufman pivoting has a negative
+			// determinant, hence an eigenvalue of each sign.
+			pos++
+			neg++
+			k++
+			continue
+		}
+		switch v := f.d[k]; {
+		case v > 0:
+			pos++
+		case v < 0:
+			neg++
+		default:
+			zero++
+		}
+	}
+	return
+}
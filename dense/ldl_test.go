@@ -0,0 +1,78 @@
+package dense
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	check "launchpad.net/gocheck"
+)
+
+// check_ldl checks that f is a factorization of a, and returns it.
+func check_ldl(c *check.C, a *Dense) *LDLFactors {
+	f, ok := LDL(a)
+	c.Assert(ok, check.Equals, true)
+	n := a.Rows()
+	pa := NewDense(n, n)
+	for i, pi := range f.Perm() {
+		for j, pj := range f.Perm() {
+			pa.Set(i, j, a.Get(pi, pj))
+		}
+	}
+	ldl := Mult(Mult(f.L(), f.D(), nil), f.L().TView(), nil)
+	c.Check(Approx(ldl, pa, 1e-10), check.Equals, true)
+	return f
+}
+
+func (s *S) TestLDL(c *check.C) {
+	// A saddle-point matrix [H A'; A 0] with H positive definite
+	// and A of full rank has inertia (4, 2, 0).
+	h := rand_spd(4)
+	am, _ := randDense(4, 1, rand.NormFloat64)
+	am = am.SubmatrixView(0, 0, 2, 4)
+	kkt := NewDense(6, 6)
+	kkt.SubmatrixView(0, 0, 4, 4).SetData(h.GetData(nil))
+	kkt.SubmatrixView(4, 0, 2, 4).SetData(am.GetData(nil))
+	kkt.SubmatrixView(0, 4, 4, 2).SetData(T(am, nil).GetData(nil))
+
+	f := check_ldl(c, kkt)
+	pos, neg, zero := f.Inertia()
+	c.Check([]int{pos, neg, zero}, check.DeepEquals, []int{4, 2, 0})
+	c.Check(math.Abs(f.Det()-kkt.Det()) < 1e-9*math.Abs(kkt.Det()), check.Equals, true)
+	c.Check(Approx(Mult(kkt, f.Inv(nil), nil), eye(6), 1e-10), check.Equals, true)
+	b, _ := randDense(6, 1, rand.NormFloat64)
+	b = b.SubmatrixView(0, 0, 6, 2)
+	x := f.Solve(Clone(b))
+	c.Check(Approx(Mult(kkt, x, nil), b, 1e-10), check.Equals, true)
+
+	// Zero diagonal: a 2 by 2 pivot.
+	f = check_ldl(c, make_dense(2, 2, []float64{0, 1, 1, 0}))
+	pos, neg, zero = f.Inertia()
+	c.Check([]int{pos, neg, zero}, check.DeepEquals, []int{1, 1, 0})
+	c.Check(f.Det(), check.Equals, -1.0)
+
+	// A larger indefinite matrix, which needs all kinds of pivots.
+	a, _ := randDense(40, 1, rand.NormFloat64)
+	a = Add(a, T(a, nil), nil)
+	f = check_ldl(c, a)
+	pos, neg, zero = f.Inertia()
+	c.Check(pos+neg, check.Equals, 40)
+	c.Check(Approx(Mult(a, f.Inv(nil), nil), eye(40), 1e-8), check.Equals, true)
+
+	// Singular.
+	f = check_ldl(c, make_dense(3, 3, []float64{
+		1, 1, 0,
+		1, 1, 0,
+		0, 0, -2,
+	}))
+	pos, neg, zero = f.Inertia()
+	c.Check([]int{pos, neg, zero}, check.DeepEquals, []int{1, 1, 1})
+	c.Check(f.Det(), check.Equals, 0.0)
+	_, e := f.TrySolve(NewDense(3, 1))
+	c.Check(e, check.Equals, ErrSingular)
+	_, e = f.TrySolve(NewDense(2, 1))
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+
+	_, ok := LDL(NewDense(2, 3))
+	c.Check(ok, check.Equals, false)
+}
@@ -0,0 +1,161 @@
+package dense
+
+import (
+	"math"
+
+	"github.com/gonum/blas"
+)
+
+// LQFactor contains the LQ decomposition a = L * Q of an m by n matrix a
+// with m <= n, where L is m by m lower triangular and Q is m by n with
+// orthonormal rows. It is the transpose of the QR decomposition of a',
+// and serves underdetermined systems as QR serves overdetermined ones.
+type LQFactor struct {
+	LQ    *Dense
+	lDiag []float64
+}
+
+// LQ computes the LQ decomposition of an m-by-n matrix a with m <= n by
+// Householder reflections of the rows. LQ will panic with ErrInShape if
+// m > n; TryLQ returns the error instead. The matrix a is overwritten by
+// the decomposition, unless it is not a *Dense, in which case it is
+// copied first.
+func LQ(a Matrix) LQFactor {
+	f, e := TryLQ(a)
+	if e != nil {
+		panic(e)
+	}
+	return f
+}
+
+// TryLQ is LQ returning an error instead of panicking.
+func TryLQ(a Matrix) (LQFactor, error) {
+	m, n := a.Dims()
+	if m > n {
+		return LQFactor{}, shape_error("LQ", ErrInShape, a)
+	}
+
+	lq := as_dense(a)
+	lDiag := make([]float64, m)
+
+	for k := 0; k < m; k++ {
+		// The k-th Householder vector, from row k.
+		v := lq.RowView(k)[k:]
+		var norm float64
+		for _, x := range v {
+			norm = math.Hypot(norm, x)
+		}
+
+		if norm != 0 {
+			if v[0] < 0 {
+				norm = -norm
+			}
+			for i := range v {
+				v[i] /= norm
+			}
+			v[0]++
+
+			// Apply transformation to remaining rows.
+			for i := k + 1; i < m; i++ {
+				row := lq.RowView(i)[k:]
+				s := -dot(v, row) / v[0]
+				for j, x := range v {
+					row[j] += s * x
+				}
+			}
+		}
+		lDiag[k] = -norm
+	}
+
+	return LQFactor{lq, lDiag}, nil
+}
+
+// IsFullRank returns whether the L matrix and hence a has full rank.
+func (f LQFactor) IsFullRank() bool {
+	for _, v := range f.lDiag {
+		if v == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// L returns the lower triangular factor for the LQ decomposition.
+func (f LQFactor) L() *TriDense {
+	m := len(f.lDiag)
+	l := NewDense(m, m)
+	for i, v := range f.lDiag {
+		copy(l.RowView(i), f.LQ.RowView(i)[:i])
+		l.Set(i, i, v)
+	}
+	return TriDenseView(l, blas.Lower, blas.NonUnit)
+}
+
+// Q generates and returns the (economy-sized) factor with orthonormal rows.
+func (f LQFactor) Q() *Dense {
+	lq := f.LQ
+	m, n := lq.Dims()
+	q := NewDense(m, n)
+
+	// Q' is formed as in QRFactor.Q, with the vectors in rows.
+	for k := m - 1; k >= 0; k-- {
+		q.Set(k, k, 1)
+		v := lq.RowView(k)[k:]
+		if v[0] == 0 {
+			continue
+		}
+		for j := k; j < m; j++ {
+			row := q.RowView(j)[k:]
+			s := -dot(v, row) / v[0]
+			for i, x := range v {
+				row[i] += s * x
+			}
+		}
+	}
+
+	return q
+}
+
+// Solve computes the minimum norm solution of a.x = b where b has as many
+// rows as a: of all x that satisfy a.x = b, it returns the one of smallest
+// two norm. Solve will panic if a is not full rank. The matrix b is
+// overwritten during the call; x is newly allocated, with as many rows
+// as a has cols.
+func (f LQFactor) Solve(b *Dense) (x *Dense) {
+	return must_dense(f.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f LQFactor) TrySolve(b *Dense) (x *Dense, e error) {
+	lq := f.LQ
+	m, n := lq.Dims()
+	bm, bn := b.Dims()
+	if bm != m {
+		return nil, shape_error("LQFactor.Solve", ErrShapes, lq, b)
+	}
+	if !f.IsFullRank() {
+		return nil, ErrRankDeficient
+	}
+
+	// Solve L*Y = B, and pad Y with zeros.
+	SolveTri(f.L(), false, b)
+	x = NewDense(n, bn)
+	Copy(x.SubmatrixView(0, 0, m, bn), b)
+
+	// Compute X = transpose(Q)*Y.
+	for k := m - 1; k >= 0; k-- {
+		v := lq.RowView(k)[k:]
+		for j := 0; j < bn; j++ {
+			var s float64
+			for i, vi := range v {
+				s += vi * x.Get(k+i, j)
+			}
+			s /= -v[0]
+			for i, vi := range v {
+				x.Set(k+i, j, x.Get(k+i, j)+s*vi)
+			}
+		}
+	}
+
+	return x, nil
+}
@@ -0,0 +1,70 @@
+package dense
+
+import (
+	"errors"
+
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestLQ(c *check.C) {
+	for _, test := range []struct {
+		a    [][]float64
+		name string
+	}{
+		{
+			name: "Square",
+			a: [][]float64{
+				{1.3, 2.4, 8.9},
+				{-2.6, 8.7, 9.1},
+				{5.6, 5.8, 2.1},
+			},
+		},
+		{
+			name: "Wide",
+			a: [][]float64{
+				{1.3, -2.6, 5.6, 19.4},
+				{2.4, 8.7, 5.8, 5.2},
+				{8.9, 9.1, 2.1, -26.1},
+			},
+		},
+	} {
+		a := flatten2dense(test.a)
+		lf := LQ(Clone(a))
+		l := lf.L()
+		q := lf.Q()
+
+		c.Check(isOrthogonal(T(q, nil)), check.Equals, true, check.Commentf("Test %v: Q not orthogonal", test.name))
+		c.Check(isUpperTriangular(l.TView()), check.Equals, true, check.Commentf("Test %v: L not lower triangular", test.name))
+		c.Check(Approx(a, Mult(l, q, nil), 1e-13), check.Equals, true, check.Commentf("Test %v: L*Q != A", test.name))
+	}
+
+	_, e := TryLQ(NewDense(3, 2))
+	c.Check(errors.Is(e, ErrInShape), check.Equals, true)
+}
+
+func (s *S) TestLQSolve(c *check.C) {
+	a := make_dense(2, 4, []float64{
+		1, 2, 0, -1,
+		0, 1, 3, 2,
+	})
+	b := make_dense(2, 2, []float64{1, 2, 3, -1})
+
+	// The minimum norm solution is a' * (a * a')^-1 * b.
+	want := Mult(a.TView(), LU(Mult(a, a.TView(), nil)).Solve(Clone(b)), nil)
+	x := LQ(Clone(a)).Solve(Clone(b))
+	c.Check(Approx(x, want, 1e-13), check.Equals, true)
+	c.Check(Approx(Mult(a, x, nil), b, 1e-13), check.Equals, true)
+
+	// On a random wide matrix, it agrees with the min-norm solution of
+	// QRCP.
+	ar, br := rand_rank(3, 5, 3), rand_rank(3, 1, 1)
+	x = LQ(Clone(ar)).Solve(Clone(br))
+	c.Check(Approx(x, QRCP(Clone(ar)).SolveMinNorm(Clone(br), 2.2204e-16), 1e-8), check.Equals, true)
+
+	_, e := LQ(make_dense(2, 3, []float64{1, 0, 0, 2, 0, 0})).TrySolve(NewDense(2, 1))
+	c.Check(e, check.Equals, ErrRankDeficient)
+	_, e = TrySolve(make_dense(2, 3, []float64{1, 0, 0, 2, 0, 0}), NewDense(2, 1))
+	c.Check(e, check.Equals, ErrRankDeficient)
+	_, e = LQ(Clone(a)).TrySolve(NewDense(3, 1))
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+}
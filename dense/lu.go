@@ -7,6 +7,8 @@ package dense
 
 import (
 	"math"
+
+	"github.com/gonum/blas"
 )
 
 type LUFactors struct {
@@ -30,11 +32,29 @@ type LUFactors struct {
 //
 // The input matrix a is modified in place and contained in the output.
 // If this is not desired, pass in a clone of the source matrix as a.
+// If a is not a *Dense, e.g. a Transpose view, it is copied first
+// and left unchanged.
 //
-// Use a "left-looking", dot-product, Crout/Doolittle algorithm.
-func LU(a *Dense) LUFactors {
-	lu := a
+// LU is LUBlocked with the default block size.
+func LU(a Matrix) LUFactors {
+	return LUBlocked(a, 0)
+}
+
+// lu_block is the default block size of LUBlocked.
+const lu_block = 64
+
+// LUBlocked is LU by the right-looking blocked algorithm of LAPACK DGETRF,
+// which does most of the work in Dtrsm and Dgemm of the registered BLAS.
+// Each step factorizes a panel of nb cols by Gaussian elimination, then
+// updates the rows to its right and the trailing matrix below it.
+// A block size nb <= 0 selects the default, 64. Matrices with no more
+// than nb cols are factorized unblocked.
+func LUBlocked(a Matrix, nb int) LUFactors {
+	lu := as_dense(a)
 	m, n := lu.Dims()
+	if nb <= 0 {
+		nb = lu_block
+	}
 
 	piv := make([]int, m)
 	for i := range piv {
@@ -42,48 +62,68 @@ func LU(a *Dense) LUFactors {
 	}
 	sign := 1
 
-	luColj := make([]float64, m)
-
-	// Outer loop.
-	for j := 0; j < n; j++ {
-
-		// Make a copy of the j-th column to localize references.
-		lu.GetCol(j, luColj)
-
-		// Apply previous transformations.
-		for i := 0; i < m; i++ {
-			luRowi := lu.RowView(i)
-
-			// Most of the time is spent in the following dot product.
-			kmax := smaller(i, j)
-			s := dot(luRowi[:kmax], luColj[:kmax])
+	for j := 0; j < smaller(m, n); j += nb {
+		jb := smaller(nb, smaller(m, n)-j)
+		sign *= lu_panel(lu, j, jb, piv)
 
-			luColj[i] -= s
-			luRowi[j] = luColj[i]
+		right := n - j - jb
+		if right == 0 {
+			continue
 		}
+		// A12 = L11 \ A12
+		blasEngine.Dtrsm(blasOrder, blas.Left, blas.Lower, blas.NoTrans, blas.Unit,
+			jb, right, 1, lu.data[lu.idx(j, j):], lu.stride,
+			lu.data[lu.idx(j, j+jb):], lu.stride)
+
+		below := m - j - jb
+		if below == 0 {
+			continue
+		}
+		// A22 -= A21 * A12
+		blasEngine.Dgemm(blasOrder, blas.NoTrans, blas.NoTrans, below, right, jb,
+			-1, lu.data[lu.idx(j+jb, j):], lu.stride,
+			lu.data[lu.idx(j, j+jb):], lu.stride,
+			1, lu.data[lu.idx(j+jb, j+jb):], lu.stride)
+	}
 
-		// Find pivot and exchange if necessary.
-		p := j
-		for i := j + 1; i < m; i++ {
-			if math.Abs(luColj[i]) > math.Abs(luColj[p]) {
+	return LUFactors{lu, piv, sign}
+}
+
+// lu_panel factorizes cols j to j+jb of lu, from row j down, by Gaussian
+// elimination with partial pivoting. Rows are exchanged in full, which
+// applies the exchanges to the factors on the left and to the cols on
+// the right. It records them in piv, and returns their sign.
+func lu_panel(lu *Dense, j, jb int, piv []int) int {
+	m, _ := lu.Dims()
+	sign := 1
+	for k := j; k < j+jb; k++ {
+		// Find pivot.
+		p := k
+		for i := k + 1; i < m; i++ {
+			if math.Abs(lu.Get(i, k)) > math.Abs(lu.Get(p, k)) {
 				p = i
 			}
 		}
-		if p != j {
-			swap(lu.RowView(p), lu.RowView(j))
-			piv[p], piv[j] = piv[j], piv[p]
+
+		// Exchange if necessary.
+		if p != k {
+			swap(lu.RowView(p), lu.RowView(k))
+			piv[p], piv[k] = piv[k], piv[p]
 			sign = -sign
 		}
 
-		// Compute multipliers.
-		if v := lu.Get(j, j); j < m && v != 0 {
-			for i := j + 1; i < m; i++ {
-				lu.Set(i, j, lu.Get(i, j)/v)
+		// Compute multipliers and eliminate k-th column within the panel.
+		if lu.Get(k, k) != 0 {
+			rowk := lu.RowView(k)[:j+jb]
+			for i := k + 1; i < m; i++ {
+				rowi := lu.RowView(i)[:j+jb]
+				vik := rowi[k] / rowk[k]
+				rowi[k] = vik
+				add_scaled(rowi[k+1:], rowk[k+1:], -vik, rowi[k+1:])
 			}
 		}
 	}
-
-	return LUFactors{lu, piv, sign}
+	return sign
 }
 
 // LUGaussian performs an LU Decomposition for an m-by-n matrix a using Gaussian elimination.
@@ -103,10 +143,12 @@ func LU(a *Dense) LUFactors {
 //
 // The input matrix a is modified in place and contained in the output.
 // If this is not desired, pass in a clone of the source matrix as a.
-func LUGaussian(a *Dense) LUFactors {
+// If a is not a *Dense, e.g. a Transpose view, it is copied first
+// and left unchanged.
+func LUGaussian(a Matrix) LUFactors {
 	// Initialize.
-	m, n := a.Dims()
-	lu := a
+	lu := as_dense(a)
+	m, n := lu.Dims()
 
 	piv := make([]int, m)
 	for i := range piv {
@@ -158,31 +200,40 @@ func (f LUFactors) IsSingular() bool {
 	return false
 }
 
-// L returns the lower triangular factor of the LU decomposition.
-func (f LUFactors) L() *Dense {
+// L returns the unit lower triangular factor of the LU decomposition.
+func (f LUFactors) L() *TriDense {
 	m, n := f.lu.Dims()
 	l := NewDense(m, n)
 	CopyLower(l, f.lu)
 	l.FillDiag(1)
 
-	return l
+	return TriDenseView(l, blas.Lower, blas.Unit)
 }
 
 // U returns the upper triangular factor of the LU decomposition.
-func (f LUFactors) U() *Dense {
+func (f LUFactors) U() *TriDense {
 	m, n := f.lu.Dims()
 	u := NewDense(m, n)
 	CopyUpper(u, f.lu)
 	CopyDiag(u, f.lu)
-	return u
+	return TriDenseView(u, blas.Upper, blas.NonUnit)
 }
 
 // Det returns the determinant of matrix a decomposed into lu. The matrix
 // a must have been square.
 func (f LUFactors) Det() float64 {
+	d, e := f.TryDet()
+	if e != nil {
+		panic(e)
+	}
+	return d
+}
+
+// TryDet is Det returning an error instead of panicking.
+func (f LUFactors) TryDet() (float64, error) {
 	m, n := f.lu.Dims()
 	if m != n {
-		panic(errSquare)
+		return 0, shape_error("LUFactors.Det", ErrSquare, f.lu)
 	}
 
 	// Product of diagonal elements.
@@ -191,7 +242,7 @@ func (f LUFactors) Det() float64 {
 		d *= f.lu.data[k]
 		k += f.lu.stride + 1
 	}
-	return d
+	return d, nil
 }
 
 // Solve computes a solution of a.x = b where b has as many rows as a. A matrix x
@@ -199,35 +250,33 @@ func (f LUFactors) Det() float64 {
 // if a is singular. The matrix b is overwritten during the call, and is
 // returned.
 func (f LUFactors) Solve(b *Dense) *Dense {
+	return must_dense(f.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f LUFactors) TrySolve(b *Dense) (*Dense, error) {
 	m, n := f.lu.Dims()
 	if b.Rows() != m {
-		panic(errShapes)
+		return nil, shape_error("LUFactors.Solve", ErrShapes, f.lu, b)
 	}
 	if f.IsSingular() {
-		panic(errSingular)
+		return nil, ErrSingular
 	}
 
 	// Copy right hand side with pivoting
 	pivotRows(b, f.pivot)
 
+	// Both factors are packed in the leading n by n block of lu.
+	lu := f.lu.SubmatrixView(0, 0, n, n)
+	x := b.SubmatrixView(0, 0, n, b.Cols())
+
 	// Solve L*Y = B(piv,:)
-	for k := 0; k < n; k++ {
-		for i := k + 1; i < n; i++ {
-			add_scaled(b.RowView(i), b.RowView(k),
-				-f.lu.Get(i, k), b.RowView(i))
-		}
-	}
+	SolveTri(TriDenseView(lu, blas.Lower, blas.Unit), false, x)
 
 	// Solve U*X = Y;
-	for k := n - 1; k >= 0; k-- {
-		scale(b.RowView(k), 1./f.lu.Get(k, k), b.RowView(k))
-		for i := 0; i < k; i++ {
-			add_scaled(b.RowView(i), b.RowView(k), -f.lu.Get(i, k),
-				b.RowView(i))
-		}
-	}
+	SolveTri(TriDenseView(lu, blas.Upper, blas.NonUnit), false, x)
 
-	return b
+	return b, nil
 }
 
 func pivotRows(a *Dense, piv []int) *Dense {
@@ -5,6 +5,10 @@
 package dense
 
 import (
+	"math"
+	"math/rand"
+	"testing"
+
 	check "launchpad.net/gocheck"
 )
 
@@ -58,8 +62,8 @@ func (s *S) TestLUD(c *check.C) {
 			c.Check(Equal(u, t.u), check.Equals, true)
 		}
 
-		l = Mult(l, u, nil)
-		c.Check(Approx(l, pivotRows(Clone(t.a), lf.pivot), 1e-12), check.Equals, true)
+		lu := Mult(l, u, nil)
+		c.Check(Approx(lu, pivotRows(Clone(t.a), lf.pivot), 1e-12), check.Equals, true)
 
 		x := lf.Solve(eye(3))
 		t.a = Mult(t.a, x, nil)
@@ -117,8 +121,8 @@ func (s *S) TestLUDGaussian(c *check.C) {
 			c.Check(Equal(u, t.u), check.Equals, true)
 		}
 
-		l = Mult(l, u, nil)
-		c.Check(Approx(l, pivotRows(Clone(t.a), lf.pivot), 1e-12), check.Equals, true)
+		lu := Mult(l, u, nil)
+		c.Check(Approx(lu, pivotRows(Clone(t.a), lf.pivot), 1e-12), check.Equals, true)
 
 		aInv := Inv(Clone(t.a), nil)
 		aInv = Mult(aInv, t.a, nil)
@@ -129,3 +133,64 @@ func (s *S) TestLUDGaussian(c *check.C) {
 		c.Check(Approx(t.a, eye(3), 1e-12), check.Equals, true)
 	}
 }
+
+func (s *S) TestLUBlocked(c *check.C) {
+	for _, t := range []struct {
+		m, n, nb int
+	}{
+		{37, 37, 4},
+		{37, 37, 64},
+		{40, 25, 8},
+		{25, 40, 8},
+		{50, 50, 7},
+	} {
+		a := NewDense(t.m, t.n)
+		for i := range a.data {
+			a.data[i] = rand.NormFloat64()
+		}
+		lf := LUBlocked(Clone(a), t.nb)
+		k := smaller(t.m, t.n)
+		l := lf.L().Full(nil).SubmatrixView(0, 0, t.m, k)
+		lu := Mult(l, lf.U().Full(nil).SubmatrixView(0, 0, k, t.n), nil)
+		c.Check(Approx(lu, pivotRows(Clone(a), lf.pivot), 1e-12), check.Equals, true,
+			check.Commentf("%dx%d, nb %d", t.m, t.n, t.nb))
+
+		// Partial pivoting bounds the multipliers by 1.
+		for i := 0; i < t.m; i++ {
+			for j := 0; j < i && j < k; j++ {
+				c.Check(math.Abs(l.At(i, j)) <= 1, check.Equals, true)
+			}
+		}
+
+		if t.m == t.n {
+			g := LUGaussian(Clone(a))
+			c.Check(math.Abs(lf.Det()-g.Det()) <= 1e-9*math.Abs(g.Det()), check.Equals, true)
+			x := lf.Solve(eye(t.n))
+			c.Check(Approx(Mult(a, x, nil), eye(t.n), 1e-10), check.Equals, true)
+		}
+	}
+
+	// A singular matrix.
+	a := make_dense(4, 4, []float64{
+		1, 2, 3, 4,
+		2, 4, 6, 8,
+		0, 1, 0, 1,
+		1, 0, 1, 0,
+	})
+	lf := LUBlocked(Clone(a), 2)
+	c.Check(lf.IsSingular(), check.Equals, true)
+	c.Check(Approx(Mult(lf.L(), lf.U(), nil), pivotRows(Clone(a), lf.pivot), 1e-14), check.Equals, true)
+}
+
+func BenchmarkLU100(b *testing.B)         { luBench(b, 100, LU) }
+func BenchmarkLU500(b *testing.B)         { luBench(b, 500, LU) }
+func BenchmarkLUGaussian100(b *testing.B) { luBench(b, 100, LUGaussian) }
+func BenchmarkLUGaussian500(b *testing.B) { luBench(b, 500, LUGaussian) }
+func luBench(b *testing.B, size int, f func(Matrix) LUFactors) {
+	b.StopTimer()
+	a, _ := randDense(size, 1, rand.NormFloat64)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		f(Clone(a))
+	}
+}
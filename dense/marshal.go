@@ -0,0 +1,303 @@
+package dense
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Serialization of Dense and of the factorization types.
+//
+// The binary form of a Dense is its number of rows and number of cols
+// as little-endian int64, followed by the elements in row major as
+// little-endian float64. The JSON form is an array of rows, each an
+// array of numbers. Submatrix views are compacted, that is, only the
+// elements of the view are written.
+//
+// The factorization types are encoded by gob in their binary form,
+// and as JSON objects otherwise. Since they implement
+// encoding.BinaryMarshaler, they can be gob-encoded as well.
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m *Dense) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 16+8*m.rows*m.cols)
+	binary.LittleEndian.PutUint64(buf, uint64(m.rows))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(m.cols))
+	k := 16
+	for i := 0; i < m.rows; i++ {
+		for _, v := range m.RowView(i) {
+			binary.LittleEndian.PutUint64(buf[k:], math.Float64bits(v))
+			k += 8
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The receiver is replaced by a newly allocated matrix;
+// if it was a view, the viewed matrix is not touched.
+func (m *Dense) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("%w: binary Dense: short header", ErrFormat)
+	}
+	r := int64(binary.LittleEndian.Uint64(data))
+	c := int64(binary.LittleEndian.Uint64(data[8:]))
+	if r < 0 || c < 0 || (r > 0 && c > int64(len(data))/r) ||
+		int64(len(data)-16) != 8*r*c {
+		return fmt.Errorf("%w: binary Dense: %d bytes for %dx%d matrix",
+			ErrFormat, len(data), r, c)
+	}
+	*m = *NewDense(int(r), int(c))
+	for i := range m.data {
+		m.data[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[16+8*i:]))
+	}
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (m *Dense) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (m *Dense) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
+
+// MarshalJSON implements json.Marshaler.
+// The matrix is written as an array of rows.
+// It is an error for the matrix to contain NaN or infinite values,
+// which JSON can not represent.
+func (m *Dense) MarshalJSON() ([]byte, error) {
+	rows := make([][]float64, m.rows)
+	for i := range rows {
+		rows[i] = m.RowView(i)
+	}
+	return json.Marshal(rows)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// All rows must have the same length.
+// The receiver is replaced by a newly allocated matrix.
+func (m *Dense) UnmarshalJSON(data []byte) error {
+	var rows [][]float64
+	if e := json.Unmarshal(data, &rows); e != nil {
+		return e
+	}
+	c := 0
+	if len(rows) > 0 {
+		c = len(rows[0])
+	}
+	out := NewDense(len(rows), c)
+	for i, row := range rows {
+		if len(row) != c {
+			return fmt.Errorf("%w: JSON Dense: row %d has length %d, expect %d",
+				ErrFormat, i, len(row), c)
+		}
+		out.SetRow(i, row)
+	}
+	*m = *out
+	return nil
+}
+
+// gob_marshal gob-encodes v.
+func gob_marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if e := gob.NewEncoder(&b).Encode(v); e != nil {
+		return nil, e
+	}
+	return b.Bytes(), nil
+}
+
+// gob_unmarshal gob-decodes data into v.
+func gob_unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// The exported mirrors of the factorization types,
+// which the encoding packages can see into.
+
+type luFactorsData struct {
+	LU    *Dense
+	Pivot []int
+	Sign  int
+}
+
+type qrFactorData struct {
+	QR    *Dense
+	RDiag []float64
+}
+
+type cholFactorsData struct {
+	L *Dense
+}
+
+type svdFactorsData struct {
+	U     *Dense
+	Sigma []float64
+	V     *Dense
+	M, N  int
+}
+
+type eigenFactorsData struct {
+	V    *Dense
+	D, E []float64
+	Sym  bool
+}
+
+func (f LUFactors) data() luFactorsData { return luFactorsData{f.lu, f.pivot, f.sign} }
+
+func (f *LUFactors) set(d luFactorsData) { *f = LUFactors{d.LU, d.Pivot, d.Sign} }
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f LUFactors) MarshalBinary() ([]byte, error) { return gob_marshal(f.data()) }
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *LUFactors) UnmarshalBinary(b []byte) error {
+	var d luFactorsData
+	if e := gob_unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f LUFactors) MarshalJSON() ([]byte, error) { return json.Marshal(f.data()) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *LUFactors) UnmarshalJSON(b []byte) error {
+	var d luFactorsData
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+func (f QRFactor) data() qrFactorData { return qrFactorData{f.QR, f.rDiag} }
+
+func (f *QRFactor) set(d qrFactorData) { *f = QRFactor{d.QR, d.RDiag} }
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f QRFactor) MarshalBinary() ([]byte, error) { return gob_marshal(f.data()) }
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *QRFactor) UnmarshalBinary(b []byte) error {
+	var d qrFactorData
+	if e := gob_unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f QRFactor) MarshalJSON() ([]byte, error) { return json.Marshal(f.data()) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *QRFactor) UnmarshalJSON(b []byte) error {
+	var d qrFactorData
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (ch *CholFactors) MarshalBinary() ([]byte, error) {
+	return gob_marshal(cholFactorsData{ch.l})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (ch *CholFactors) UnmarshalBinary(b []byte) error {
+	var d cholFactorsData
+	if e := gob_unmarshal(b, &d); e != nil {
+		return e
+	}
+	ch.l = d.L
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ch *CholFactors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cholFactorsData{ch.l})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ch *CholFactors) UnmarshalJSON(b []byte) error {
+	var d cholFactorsData
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	ch.l = d.L
+	return nil
+}
+
+func (f SVDFactors) data() svdFactorsData {
+	return svdFactorsData{f.U, f.Sigma, f.V, f.m, f.n}
+}
+
+func (f *SVDFactors) set(d svdFactorsData) {
+	*f = SVDFactors{d.U, d.Sigma, d.V, d.M, d.N}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f SVDFactors) MarshalBinary() ([]byte, error) { return gob_marshal(f.data()) }
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *SVDFactors) UnmarshalBinary(b []byte) error {
+	var d svdFactorsData
+	if e := gob_unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f SVDFactors) MarshalJSON() ([]byte, error) { return json.Marshal(f.data()) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *SVDFactors) UnmarshalJSON(b []byte) error {
+	var d svdFactorsData
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+func (f EigenFactors) data() eigenFactorsData { return eigenFactorsData{f.V, f.d, f.e, f.sym} }
+
+func (f *EigenFactors) set(d eigenFactorsData) { *f = EigenFactors{d.V, d.D, d.E, d.Sym} }
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f EigenFactors) MarshalBinary() ([]byte, error) { return gob_marshal(f.data()) }
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *EigenFactors) UnmarshalBinary(b []byte) error {
+	var d eigenFactorsData
+	if e := gob_unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f EigenFactors) MarshalJSON() ([]byte, error) { return json.Marshal(f.data()) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *EigenFactors) UnmarshalJSON(b []byte) error {
+	var d eigenFactorsData
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	f.set(d)
+	return nil
+}
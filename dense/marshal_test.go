@@ -0,0 +1,95 @@
+package dense
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestMarshalDense(c *check.C) {
+	m := rand_dense(5, 6)
+	sub := m.SubmatrixView(1, 2, 3, 2)
+
+	b, e := sub.MarshalBinary()
+	c.Assert(e, check.IsNil)
+	c.Check(len(b), check.Equals, 16+8*6)
+	var back Dense
+	c.Assert(back.UnmarshalBinary(b), check.IsNil)
+	c.Check(Equal(&back, sub), check.Equals, true)
+	c.Check(back.Contiguous(), check.Equals, true)
+
+	c.Check(errors.Is(back.UnmarshalBinary(b[:20]), ErrFormat), check.Equals, true)
+
+	var buf bytes.Buffer
+	c.Assert(gob.NewEncoder(&buf).Encode(sub), check.IsNil)
+	var gback *Dense
+	c.Assert(gob.NewDecoder(&buf).Decode(&gback), check.IsNil)
+	c.Check(Equal(gback, sub), check.Equals, true)
+
+	j, e := json.Marshal(flatten2dense([][]float64{{1, 2.5}, {3, -4}}).SubmatrixView(0, 1, 2, 1))
+	c.Assert(e, check.IsNil)
+	c.Check(string(j), check.Equals, `[[2.5],[-4]]`)
+	var jback Dense
+	c.Assert(json.Unmarshal([]byte(`[[1, 2], [3, 4], [5, 6]]`), &jback), check.IsNil)
+	c.Check(Equal(&jback, flatten2dense([][]float64{{1, 2}, {3, 4}, {5, 6}})), check.Equals, true)
+	c.Check(errors.Is(json.Unmarshal([]byte(`[[1, 2], [3]]`), &jback), ErrFormat), check.Equals, true)
+}
+
+func (s *S) TestMarshalFactors(c *check.C) {
+	a := make_dense(3, 3, []float64{
+		4, 1, 1,
+		1, 2, 3,
+		1, 3, 6,
+	})
+	b := make_dense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+
+	// roundtrip encodes src by gob and JSON, decoding into the
+	// values pointed to by gdst and jdst.
+	roundtrip := func(src, gdst, jdst interface{}) {
+		var buf bytes.Buffer
+		c.Assert(gob.NewEncoder(&buf).Encode(src), check.IsNil)
+		c.Assert(gob.NewDecoder(&buf).Decode(gdst), check.IsNil)
+		j, e := json.Marshal(src)
+		c.Assert(e, check.IsNil)
+		c.Assert(json.Unmarshal(j, jdst), check.IsNil)
+	}
+
+	lu := LU(Clone(a))
+	var lug, luj LUFactors
+	roundtrip(lu, &lug, &luj)
+	want := lu.Solve(Clone(b))
+	c.Check(Equal(lug.Solve(Clone(b)), want), check.Equals, true)
+	c.Check(Equal(luj.Solve(Clone(b)), want), check.Equals, true)
+	c.Check(luj.Det(), check.Equals, lu.Det())
+
+	qr := QR(Clone(a))
+	var qrg, qrj QRFactor
+	roundtrip(qr, &qrg, &qrj)
+	want = qr.Solve(Clone(b))
+	c.Check(Equal(qrg.Solve(Clone(b)), want), check.Equals, true)
+	c.Check(Equal(qrj.Solve(Clone(b)), want), check.Equals, true)
+
+	ch, _ := Chol(a)
+	var chg, chj CholFactors
+	roundtrip(ch, &chg, &chj)
+	c.Check(Equal(chg.L(), ch.L()), check.Equals, true)
+	c.Check(Equal(chj.L(), ch.L()), check.Equals, true)
+
+	sv := SVD(Clone(a), 2.2204e-16, 1e-300, true, false)
+	var svg, svj SVDFactors
+	roundtrip(sv, &svg, &svj)
+	c.Check(Equal(svg.U, sv.U), check.Equals, true)
+	c.Check(svg.V, check.IsNil)
+	c.Check(svj.Sigma, check.DeepEquals, sv.Sigma)
+	c.Check(svj.Cond(), check.Equals, sv.Cond())
+
+	ei := Eigen(Clone(a), 2.2204e-16)
+	var eig, eij EigenFactors
+	roundtrip(ei, &eig, &eij)
+	c.Check(Equal(eig.D(), ei.D()), check.Equals, true)
+	c.Check(Equal(eij.V, ei.V), check.Equals, true)
+	c.Check(eig.Symmetric(), check.Equals, ei.Symmetric())
+	c.Check(eij.Values(), check.DeepEquals, ei.Values())
+}
@@ -0,0 +1,105 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+)
+
+// Matrix is the interface for read-only access to the elements
+// of a matrix. *Dense implements it, as do the zero-copy views
+// Transpose and Scaled.
+type Matrix interface {
+	// Dims returns the number of rows and cols.
+	Dims() (r, c int)
+
+	// At returns the element at row r, col c.
+	At(r, c int) float64
+}
+
+// At returns the element at row r, col c.
+// It is the same as Get, and makes Dense a Matrix.
+func (m *Dense) At(r, c int) float64 {
+	return m.data[r*m.stride+c]
+}
+
+// TView returns a view of the transpose of m.
+// No data is copied; changes to m are reflected in the view.
+// To get a transposed copy, use T.
+func (m *Dense) TView() Transpose {
+	return Transpose{m}
+}
+
+// Transpose is a zero-copy view of the transpose of a Matrix.
+type Transpose struct {
+	Matrix Matrix
+}
+
+func (t Transpose) Dims() (r, c int) {
+	c, r = t.Matrix.Dims()
+	return r, c
+}
+
+func (t Transpose) At(r, c int) float64 {
+	return t.Matrix.At(c, r)
+}
+
+// Scaled is a zero-copy view of a Matrix whose elements
+// are multiplied by Alpha.
+type Scaled struct {
+	Matrix Matrix
+	Alpha  float64
+}
+
+func (s Scaled) Dims() (r, c int) {
+	return s.Matrix.Dims()
+}
+
+func (s Scaled) At(r, c int) float64 {
+	return s.Alpha * s.Matrix.At(r, c)
+}
+
+// unwrap peels Transpose and Scaled views off m.
+// If what is left is a *Dense, it is returned along with
+// the accumulated transpose flag and scale factor, and ok is true.
+// Otherwise ok is false.
+func unwrap(m Matrix) (d *Dense, trans bool, alpha float64, ok bool) {
+	alpha = 1
+	for {
+		switch t := m.(type) {
+		case *Dense:
+			return t, trans, alpha, true
+		case Transpose:
+			trans = !trans
+			m = t.Matrix
+		case Scaled:
+			alpha *= t.Alpha
+			m = t.Matrix
+		default:
+			return nil, false, 1, false
+		}
+	}
+}
+
+// blas_operand returns the data of m in a form that can be passed
+// to a BLAS routine, copying m only if it is not a (possibly
+// transposed and scaled) view of a Dense.
+func blas_operand(m Matrix) (d *Dense, t blas.Transpose, alpha float64) {
+	d, trans, alpha, ok := unwrap(m)
+	if !ok {
+		return Clone(m), blas.NoTrans, 1
+	}
+	if trans {
+		return d, blas.Trans, alpha
+	}
+	return d, blas.NoTrans, alpha
+}
+
+// as_dense returns m itself if it is a *Dense;
+// otherwise it copies m into a newly allocated Dense.
+// Functions that modify their input in place use it so that
+// a non-Dense input is left untouched.
+func as_dense(m Matrix) *Dense {
+	if d, ok := m.(*Dense); ok {
+		return d
+	}
+	return Clone(m)
+}
@@ -0,0 +1,112 @@
+package dense
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+// diagonal is a Matrix that is not backed by a Dense.
+type diagonal []float64
+
+func (d diagonal) Dims() (r, c int) { return len(d), len(d) }
+
+func (d diagonal) At(r, c int) float64 {
+	if r == c {
+		return d[r]
+	}
+	return 0
+}
+
+func (s *S) TestTransposeView(c *check.C) {
+	a := make_dense(2, 3, []float64{
+		1, 2, 3,
+		4, 5, 6})
+	t := a.TView()
+	r, cc := t.Dims()
+	c.Check(r, check.Equals, 3)
+	c.Check(cc, check.Equals, 2)
+	c.Check(t.At(2, 1), check.Equals, 6.0)
+	c.Check(Equal(t, T(a, nil)), check.Equals, true)
+	c.Check(Equal(Transpose{t}, a), check.Equals, true)
+
+	// The view shares data with a.
+	a.Set(0, 2, 30)
+	c.Check(t.At(2, 0), check.Equals, 30.0)
+
+	sc := Scaled{t, 2}
+	c.Check(sc.At(2, 0), check.Equals, 60.0)
+	c.Check(Approx(sc, Scale(T(a, nil), 2, nil), 1e-15), check.Equals, true)
+}
+
+func (s *S) TestMultViews(c *check.C) {
+	a := rand_dense(5, 3)
+	b := rand_dense(5, 4)
+	at := T(a, nil)
+	bt := T(b, nil)
+
+	c.Check(Approx(Mult(a.TView(), b, nil), Mult(at, b, nil), 1e-12),
+		check.Equals, true)
+	c.Check(Approx(Mult(bt, a, nil), Mult(b.TView(), a, nil), 1e-12),
+		check.Equals, true)
+	c.Check(Approx(Mult(a.TView(), bt.TView(), nil), Mult(at, b, nil), 1e-12),
+		check.Equals, true)
+	c.Check(Approx(
+		Mult(Scaled{a.TView(), 2}, Scaled{b, -3}, nil),
+		Scale(Mult(at, b, nil), -6, nil), 1e-12),
+		check.Equals, true)
+
+	// Transposed submatrix views.
+	big := rand_dense(8, 9)
+	sub := big.SubmatrixView(2, 3, 5, 3)
+	c.Check(Approx(Mult(sub.TView(), b, nil), Mult(T(sub, nil), b, nil), 1e-12),
+		check.Equals, true)
+
+	// A Matrix that is not a Dense.
+	d := diagonal{1, 2, 3, 4, 5}
+	want := Clone(b)
+	for i := 0; i < 5; i++ {
+		scale(want.RowView(i), d[i], want.RowView(i))
+	}
+	c.Check(Approx(Mult(d, b, nil), want, 1e-14), check.Equals, true)
+}
+
+func (s *S) TestCopyMatrix(c *check.C) {
+	a := rand_dense(3, 4)
+	out := NewDense(4, 3)
+	Copy(out, a.TView())
+	c.Check(Equal(out, T(a, nil)), check.Equals, true)
+	c.Check(Equal(Clone(diagonal{1, 2}), make_dense(2, 2, []float64{1, 0, 0, 2})),
+		check.Equals, true)
+
+	panicked, _ := panics(func() { Copy(out, a) })
+	c.Check(panicked, check.Equals, true)
+}
+
+func (s *S) TestFactorizeViews(c *check.C) {
+	a := make_dense(3, 3, []float64{
+		4, 1, 1,
+		1, 2, 3,
+		1, 3, 6,
+	})
+	orig := Clone(a)
+
+	ch, ok := Chol(a.TView())
+	c.Check(ok, check.Equals, true)
+	c.Check(Approx(Mult(ch.L(), ch.L().TView(), nil), a, 1e-12), check.Equals, true)
+
+	// Factorizations of views leave the viewed matrix untouched.
+	b := make_dense(3, 3, []float64{
+		1, 2, 3,
+		0, 4, 5,
+		1, 0, 6,
+	})
+	borig := Clone(b)
+	lu := LU(b.TView())
+	c.Check(Equal(b, borig), check.Equals, true)
+	c.Check(approx(lu.Det(), borig.Det(), 1e-12), check.Equals, true)
+
+	QR(b.TView())
+	SVD(b.TView(), 2.2204e-16, 1e-300, true, true)
+	Eigen(a.TView(), 2.2204e-16)
+	c.Check(Equal(b, borig), check.Equals, true)
+	c.Check(Equal(a, orig), check.Equals, true)
+}
@@ -0,0 +1,145 @@
+package dense
+
+import (
+	"math"
+)
+
+// ModifiedChol computes the Cholesky factorization of M + E, where E is
+// a nonnegative diagonal perturbation that is zero if M is safely
+// positive definite, and otherwise is kept small while making M + E
+// positive definite with bounded factors. It returns the factorization,
+// whose Solve solves with M + E, and the diagonal of E.
+// M is only read, in its lower triangle. The success flag is false if
+// M is not square.
+//
+// This is the algorithm of Gill and Murray, in the form of Gill, Murray
+// and Wright, "Practical Optimization", 1981, section 4.4.2.2, without
+// the symmetric pivoting.
+func ModifiedChol(M Matrix) (*CholFactors, []float64, bool) {
+	n, c := M.Dims()
+	if c != n {
+		return &CholFactors{nil}, nil, false
+	}
+
+	a := NewDense(n, n)
+	var gamma, xi float64 // largest diagonal, off-diagonal elements
+	for i := 0; i < n; i++ {
+		aRowi := a.RowView(i)
+		for j := 0; j < i; j++ {
+			aRowi[j] = M.At(i, j)
+			xi = math.Max(xi, math.Abs(aRowi[j]))
+		}
+		aRowi[i] = M.At(i, i)
+		gamma = math.Max(gamma, math.Abs(aRowi[i]))
+	}
+	const eps = 2.2204e-16
+	delta := eps * math.Max(gamma+xi, 1)
+	beta2 := math.Max(gamma, eps)
+	if n > 1 {
+		beta2 = math.Max(beta2, xi/math.Sqrt(float64(n*n-1)))
+	}
+
+	// L * D * L' = M + E, built col by col; a keeps the cols
+	// c[i][s] = L[i][s] * d[s] for s < j.
+	l := NewDense(n, n)
+	d := make([]float64, n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		lRowj := l.RowView(j)
+		var theta float64
+		for i := j; i < n; i++ {
+			aRowi := a.RowView(i)
+			aRowi[j] -= dot(lRowj[:j], aRowi[:j])
+			if i > j {
+				theta = math.Max(theta, math.Abs(aRowi[j]))
+			}
+		}
+		cjj := a.Get(j, j)
+		d[j] = math.Max(math.Max(math.Abs(cjj), theta*theta/beta2), delta)
+		e[j] = d[j] - cjj
+		lRowj[j] = 1
+		for i := j + 1; i < n; i++ {
+			l.Set(i, j, a.Get(i, j)/d[j])
+		}
+	}
+
+	// The Cholesky factor is L * sqrt(D).
+	for j, v := range d {
+		s := math.Sqrt(v)
+		for i := j; i < n; i++ {
+			l.Set(i, j, l.Get(i, j)*s)
+		}
+	}
+	return &CholFactors{l}, e, true
+}
+
+// NearestPD returns a symmetric positive definite matrix near the square
+// matrix a, which Chol accepts. If a is symmetric positive definite, the
+// result equals a up to rounding.
+//
+// The nearest symmetric positive semidefinite matrix in the Frobenius
+// norm is found as in N. J. Higham, "Computing a nearest symmetric
+// positive semidefinite matrix", Linear Algebra Appl. 103, 1988: the
+// projection of the symmetric part of a onto the semidefinite cone, by
+// setting its negative eigenvalues to zero. (The alternating projections
+// of Higham's later work are needed only with further constraints, such
+// as the unit diagonal of a correlation matrix.) Eigenvalues below
+// n * eps * max|eigenvalue| are then raised to that value, and, should
+// rounding still defeat Chol, increasing multiples of it are added to
+// the diagonal.
+func NearestPD(a Matrix) *Dense {
+	return must_dense(TryNearestPD(a))
+}
+
+// TryNearestPD is NearestPD returning an error instead of panicking.
+func TryNearestPD(a Matrix) (*Dense, error) {
+	n, c := a.Dims()
+	if c != n {
+		return nil, shape_error("NearestPD", ErrSquare, a)
+	}
+
+	// The symmetric part, exactly symmetric.
+	b := NewDense(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			v := (a.At(i, j) + a.At(j, i)) / 2
+			b.Set(i, j, v)
+			b.Set(j, i, v)
+		}
+	}
+
+	ef := Eigen(b, 2.2204e-16)
+	var big float64
+	for _, v := range ef.d {
+		big = math.Max(big, math.Abs(v))
+	}
+	tau := float64(n) * 2.2204e-16 * big
+	if tau == 0 {
+		tau = 2.2204e-16
+	}
+
+	// x = V * max(D, tau) * V'
+	vd := Clone(ef.V)
+	for j, v := range ef.d {
+		v = math.Max(v, tau)
+		for i := 0; i < n; i++ {
+			vd.Set(i, j, vd.Get(i, j)*v)
+		}
+	}
+	x := Mult(vd, ef.V.TView(), nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			v := (x.Get(i, j) + x.Get(j, i)) / 2
+			x.Set(i, j, v)
+			x.Set(j, i, v)
+		}
+	}
+
+	ch := &CholFactors{nil}
+	for k := 1; !ch.Chol(x); k++ {
+		for i := 0; i < n; i++ {
+			x.Set(i, i, x.Get(i, i)+float64(k*k)*tau)
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,92 @@
+package dense
+
+import (
+	"math/rand"
+
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestModifiedChol(c *check.C) {
+	// Diagonally dominant, hence safely positive definite: no change.
+	a := make_dense(3, 3, []float64{
+		4, 1, 1,
+		1, 3, 1,
+		1, 1, 5,
+	})
+	mc, e, ok := ModifiedChol(a)
+	c.Assert(ok, check.Equals, true)
+	c.Check(e, check.DeepEquals, []float64{0, 0, 0})
+	cl, _ := Chol(a)
+	c.Check(Approx(mc.L(), cl.L(), 1e-14), check.Equals, true)
+
+	// Indefinite and singular matrices are perturbed into positive
+	// definite ones.
+	for _, a := range []*Dense{
+		make_dense(2, 2, []float64{1, 2, 2, 1}),
+		make_dense(3, 3, []float64{
+			1, 1, 1,
+			1, 1, 1,
+			1, 1, 1,
+		}),
+		near_pd(20),
+	} {
+		n := a.Rows()
+		mc, e, ok := ModifiedChol(a)
+		c.Assert(ok, check.Equals, true)
+		ae := Clone(a)
+		for i, v := range e {
+			c.Check(v >= 0, check.Equals, true)
+			ae.Set(i, i, ae.Get(i, i)+v)
+		}
+		c.Check(Approx(Mult(mc.L(), mc.L().TView(), nil), ae, 1e-10), check.Equals, true)
+		_, ok = Chol(ae)
+		c.Check(ok, check.Equals, true)
+		if n < 20 {
+			c.Check(Approx(Mult(ae, mc.Solve(eye(n)), nil), eye(n), 1e-8), check.Equals, true)
+		}
+	}
+
+	_, _, ok = ModifiedChol(NewDense(2, 3))
+	c.Check(ok, check.Equals, false)
+}
+
+// near_pd returns a sample covariance matrix of n variables from fewer
+// samples, which is singular, minus a small multiple of the identity.
+func near_pd(n int) *Dense {
+	x, _ := randDense(n, 1, rand.NormFloat64)
+	x = x.SubmatrixView(0, 0, n/2, n)
+	a := Mult(x.TView(), x, nil).Scale(1 / float64(n/2))
+	return a.Add(eye(n).Scale(-1e-6))
+}
+
+func (s *S) TestNearestPD(c *check.C) {
+	// The nearest semidefinite matrix to [1 2; 2 1], whose eigenvalues
+	// are 3 and -1, drops the latter.
+	x := NearestPD(make_dense(2, 2, []float64{1, 2, 2, 1}))
+	c.Check(Approx(x, make_dense(2, 2, []float64{1.5, 1.5, 1.5, 1.5}), 1e-12), check.Equals, true)
+	_, ok := Chol(x)
+	c.Check(ok, check.Equals, true)
+
+	// Already positive definite.
+	a := rand_spd(10)
+	c.Check(Approx(NearestPD(a), a, 1e-10), check.Equals, true)
+
+	for _, a := range []Matrix{
+		near_pd(20),
+		NewDense(4, 4),
+		make_dense(2, 2, []float64{0, 1, -1, 0}),
+		make_dense(3, 3, []float64{
+			1, 0.9, 0.7,
+			0.9, 1, 0.3,
+			0.7, 0.3, 1,
+		}).TView(),
+	} {
+		x := NearestPD(a)
+		_, ok := Chol(x)
+		c.Check(ok, check.Equals, true)
+		c.Check(symmetric(x), check.Equals, true)
+	}
+
+	_, e := TryNearestPD(NewDense(2, 3))
+	c.Check(e, check.ErrorMatches, ".*square.*")
+}
@@ -0,0 +1,280 @@
+package dense
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Support for the Matrix Market exchange format,
+// see http://math.nist.gov/MatrixMarket/formats.html
+
+const mtxBanner = "%%MatrixMarket"
+
+// ReadMatrixMarket reads a real matrix in Matrix Market format from r.
+//
+// Both the "array" (dense) and "coordinate" (sparse) formats are
+// supported, with fields "real", "integer" and "pattern" (the latter
+// only in coordinate format, where every listed entry is 1), and
+// symmetry "general", "symmetric" and "skew-symmetric".
+// For symmetric and skew-symmetric matrices, which store only the
+// lower triangle, the upper triangle is filled in.
+// Complex and Hermitian matrices are not supported.
+//
+// Errors in the input are reported as errors wrapping ErrFormat.
+func ReadMatrixMarket(r io.Reader) (*Dense, error) {
+	sc := bufio.NewScanner(r)
+	line := 0
+	next := func() (string, bool) {
+		for sc.Scan() {
+			line++
+			s := strings.TrimSpace(sc.Text())
+			if s == "" || s[0] == '%' {
+				continue
+			}
+			return s, true
+		}
+		return "", false
+	}
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: matrix market line %d: %s",
+			ErrFormat, line, fmt.Sprintf(format, args...))
+	}
+
+	// Header.
+	if !sc.Scan() {
+		if e := sc.Err(); e != nil {
+			return nil, e
+		}
+		return nil, fail("empty input")
+	}
+	line++
+	header := strings.Fields(strings.ToLower(sc.Text()))
+	if len(header) != 5 || header[0] != strings.ToLower(mtxBanner) || header[1] != "matrix" {
+		return nil, fail("bad header %q", sc.Text())
+	}
+	format, field, symmetry := header[2], header[3], header[4]
+	if format != "array" && format != "coordinate" {
+		return nil, fail("unsupported format %q", format)
+	}
+	switch field {
+	case "real", "integer":
+	case "pattern":
+		if format == "array" {
+			return nil, fail("pattern field in array format")
+		}
+	default:
+		return nil, fail("unsupported field %q", field)
+	}
+	switch symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	default:
+		return nil, fail("unsupported symmetry %q", symmetry)
+	}
+
+	// Size line.
+	s, ok := next()
+	if !ok {
+		return nil, fail("missing size line")
+	}
+	nsize := 2
+	if format == "coordinate" {
+		nsize = 3
+	}
+	size, e := parse_ints(s, nsize)
+	if e != nil {
+		return nil, fail("bad size line %q", s)
+	}
+	rows, cols := size[0], size[1]
+	if rows < 0 || cols < 0 || symmetry != "general" && rows != cols {
+		return nil, fail("bad size %dx%d for %s matrix", rows, cols, symmetry)
+	}
+	m := NewDense(rows, cols)
+
+	// set places v at (i, j), mirroring it for the symmetric types.
+	set := func(i, j int, v float64) error {
+		if i < 0 || i >= rows || j < 0 || j >= cols {
+			return fail("index (%d, %d) out of range", i+1, j+1)
+		}
+		switch symmetry {
+		case "symmetric":
+			if i < j {
+				return fail("entry (%d, %d) above the diagonal", i+1, j+1)
+			}
+			m.Set(j, i, v)
+		case "skew-symmetric":
+			if i <= j {
+				return fail("entry (%d, %d) not below the diagonal", i+1, j+1)
+			}
+			m.Set(j, i, -v)
+		}
+		m.Set(i, j, v)
+		return nil
+	}
+
+	if format == "array" {
+		// Column major; only the lower triangle for symmetric types.
+		for j := 0; j < cols; j++ {
+			start := 0
+			switch symmetry {
+			case "symmetric":
+				start = j
+			case "skew-symmetric":
+				start = j + 1
+			}
+			for i := start; i < rows; i++ {
+				s, ok := next()
+				if !ok {
+					return nil, fail("unexpected end of data")
+				}
+				v, e := strconv.ParseFloat(s, 64)
+				if e != nil {
+					return nil, fail("bad value %q", s)
+				}
+				set(i, j, v)
+			}
+		}
+	} else {
+		nnz := size[2]
+		nfield := 3
+		if field == "pattern" {
+			nfield = 2
+		}
+		for k := 0; k < nnz; k++ {
+			s, ok := next()
+			if !ok {
+				return nil, fail("expected %d entries, got %d", nnz, k)
+			}
+			f := strings.Fields(s)
+			if len(f) != nfield {
+				return nil, fail("bad entry %q", s)
+			}
+			ij, e := parse_ints(f[0]+" "+f[1], 2)
+			if e != nil {
+				return nil, fail("bad entry %q", s)
+			}
+			v := 1.0
+			if nfield == 3 {
+				if v, e = strconv.ParseFloat(f[2], 64); e != nil {
+					return nil, fail("bad entry %q", s)
+				}
+			}
+			if e := set(ij[0]-1, ij[1]-1, v); e != nil {
+				return nil, e
+			}
+		}
+	}
+
+	if s, ok := next(); ok {
+		return nil, fail("unexpected data %q", s)
+	}
+	if e := sc.Err(); e != nil {
+		return nil, e
+	}
+	return m, nil
+}
+
+// parse_ints parses exactly n whitespace-separated integers in s.
+func parse_ints(s string, n int) ([]int, error) {
+	f := strings.Fields(s)
+	if len(f) != n {
+		return nil, ErrFormat
+	}
+	out := make([]int, n)
+	for i, x := range f {
+		v, e := strconv.Atoi(x)
+		if e != nil {
+			return nil, e
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// WriteMatrixMarket writes m to w in Matrix Market
+// "array real general" format.
+// m may be a submatrix view; only its own elements are written.
+func WriteMatrixMarket(w io.Writer, m *Dense) error {
+	return WriteMatrixMarketAs(w, m, "array", "general")
+}
+
+// WriteMatrixMarketAs writes m to w in Matrix Market format with field
+// "real", format "array" or "coordinate" and symmetry "general",
+// "symmetric" or "skew-symmetric". In coordinate format only the nonzero
+// elements are written. For the symmetric types only the lower triangle
+// is written (without the diagonal for skew-symmetric), and m must be
+// square and exactly symmetric or skew-symmetric.
+//
+// An unsupported format or symmetry, or an m that does not have the
+// symmetry, is reported as an error wrapping ErrFormat.
+func WriteMatrixMarketAs(w io.Writer, m *Dense, format, symmetry string) error {
+	fail := func(msg string, args ...interface{}) error {
+		return fmt.Errorf("%w: matrix market: %s", ErrFormat, fmt.Sprintf(msg, args...))
+	}
+	if format != "array" && format != "coordinate" {
+		return fail("unsupported format %q", format)
+	}
+
+	// start is the first row written in col j.
+	start := func(j int) int { return 0 }
+	switch symmetry {
+	case "general":
+	case "symmetric", "skew-symmetric":
+		if m.rows != m.cols {
+			return fail("%dx%d matrix is not %s", m.rows, m.cols, symmetry)
+		}
+		sign := 1.0
+		start = func(j int) int { return j }
+		if symmetry == "skew-symmetric" {
+			sign = -1
+			start = func(j int) int { return j + 1 }
+		}
+		for i := 0; i < m.rows; i++ {
+			for j := 0; j <= i; j++ {
+				if m.Get(j, i) != sign*m.Get(i, j) {
+					return fail("matrix is not %s", symmetry)
+				}
+			}
+		}
+	default:
+		return fail("unsupported symmetry %q", symmetry)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s matrix %s real %s\n", mtxBanner, format, symmetry)
+	if format == "array" {
+		fmt.Fprintf(bw, "%d %d\n", m.rows, m.cols)
+	} else {
+		var nnz int
+		for j := 0; j < m.cols; j++ {
+			for i := start(j); i < m.rows; i++ {
+				if m.Get(i, j) != 0 {
+					nnz++
+				}
+			}
+		}
+		fmt.Fprintf(bw, "%d %d %d\n", m.rows, m.cols, nnz)
+	}
+	buf := make([]byte, 0, 64)
+	for j := 0; j < m.cols; j++ {
+		for i := start(j); i < m.rows; i++ {
+			v := m.Get(i, j)
+			buf = buf[:0]
+			if format == "coordinate" {
+				if v == 0 {
+					continue
+				}
+				buf = strconv.AppendInt(buf, int64(i+1), 10)
+				buf = append(buf, ' ')
+				buf = strconv.AppendInt(buf, int64(j+1), 10)
+				buf = append(buf, ' ')
+			}
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
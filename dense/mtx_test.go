@@ -0,0 +1,205 @@
+package dense
+
+import (
+	"bytes"
+	"errors"
+	check "launchpad.net/gocheck"
+	"strings"
+)
+
+func (s *S) TestReadMatrixMarket(c *check.C) {
+	for _, test := range []struct {
+		name, in string
+		want     [][]float64
+	}{
+		{
+			name: "array general",
+			in: `%%MatrixMarket matrix array real general
+% a comment
+2 3
+1
+4
+2
+5
+3
+6
+`,
+			want: [][]float64{{1, 2, 3}, {4, 5, 6}},
+		},
+		{
+			name: "array symmetric",
+			in: `%%MatrixMarket matrix array real symmetric
+3 3
+1
+2
+3
+4
+5
+6
+`,
+			want: [][]float64{{1, 2, 3}, {2, 4, 5}, {3, 5, 6}},
+		},
+		{
+			name: "array skew-symmetric",
+			in: `%%MatrixMarket matrix array integer skew-symmetric
+3 3
+1
+2
+3
+`,
+			want: [][]float64{{0, -1, -2}, {1, 0, -3}, {2, 3, 0}},
+		},
+		{
+			name: "coordinate general",
+			in: `%%MatrixMarket matrix coordinate real general
+%
+3 2 3
+1 1 1.5
+3 2 -2e3
+2 1 7
+`,
+			want: [][]float64{{1.5, 0}, {7, 0}, {0, -2000}},
+		},
+		{
+			name: "coordinate symmetric",
+			in: `%%MatrixMarket Matrix Coordinate Integer Symmetric
+3 3 3
+1 1 4
+3 1 2
+3 2 1
+`,
+			want: [][]float64{{4, 0, 2}, {0, 0, 1}, {2, 1, 0}},
+		},
+		{
+			name: "coordinate pattern skew-symmetric",
+			in: `%%MatrixMarket matrix coordinate pattern skew-symmetric
+2 2 1
+2 1
+`,
+			want: [][]float64{{0, -1}, {1, 0}},
+		},
+	} {
+		m, e := ReadMatrixMarket(strings.NewReader(test.in))
+		c.Assert(e, check.IsNil, check.Commentf("%s", test.name))
+		c.Check(Equal(m, flatten2dense(test.want)), check.Equals, true,
+			check.Commentf("%s: got %v", test.name, m))
+	}
+
+	for _, in := range []string{
+		"",
+		"%%MatrixMarket matrix array complex general\n1 1\n1 0\n",
+		"%%MatrixMarket matrix array pattern general\n1 1\n",
+		"%%MatrixMarket matrix array real symmetric\n2 3\n",
+		"%%MatrixMarket matrix array real general\n2 1\n1\n",
+		"%%MatrixMarket matrix array real general\n1 1\n1\n2\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 x\n",
+	} {
+		_, e := ReadMatrixMarket(strings.NewReader(in))
+		c.Check(errors.Is(e, ErrFormat), check.Equals, true, check.Commentf("%q", in))
+	}
+}
+
+func (s *S) TestWriteMatrixMarket(c *check.C) {
+	m := flatten2dense([][]float64{
+		{1, 2, 3, 4},
+		{5, 6.5, 7, 8},
+		{9, 10, 11, -1e-20},
+	})
+	sub := m.SubmatrixView(1, 1, 2, 3)
+
+	var buf bytes.Buffer
+	c.Assert(WriteMatrixMarket(&buf, sub), check.IsNil)
+	c.Check(buf.String(), check.Equals, `%%MatrixMarket matrix array real general
+2 3
+6.5
+10
+7
+11
+8
+-1e-20
+`)
+
+	back, e := ReadMatrixMarket(&buf)
+	c.Assert(e, check.IsNil)
+	c.Check(Equal(back, sub), check.Equals, true)
+}
+
+func (s *S) TestWriteMatrixMarketAs(c *check.C) {
+	sym := flatten2dense([][]float64{
+		{1, 0, 3},
+		{0, 0, -2.5},
+		{3, -2.5, 4},
+	})
+	skew := flatten2dense([][]float64{
+		{0, -1, 0},
+		{1, 0, -3},
+		{0, 3, 0},
+	})
+	for _, t := range []struct {
+		m                      *Dense
+		format, symmetry, want string
+	}{
+		{sym, "coordinate", "general", `%%MatrixMarket matrix coordinate real general
+3 3 6
+1 1 1
+3 1 3
+3 2 -2.5
+1 3 3
+2 3 -2.5
+3 3 4
+`},
+		{sym, "array", "symmetric", `%%MatrixMarket matrix array real symmetric
+3 3
+1
+0
+3
+0
+-2.5
+4
+`},
+		{sym, "coordinate", "symmetric", `%%MatrixMarket matrix coordinate real symmetric
+3 3 4
+1 1 1
+3 1 3
+3 2 -2.5
+3 3 4
+`},
+		{skew, "array", "skew-symmetric", `%%MatrixMarket matrix array real skew-symmetric
+3 3
+1
+0
+3
+`},
+		{skew, "coordinate", "skew-symmetric", `%%MatrixMarket matrix coordinate real skew-symmetric
+3 3 2
+2 1 1
+3 2 3
+`},
+	} {
+		comment := check.Commentf("%s %s", t.format, t.symmetry)
+		var buf bytes.Buffer
+		c.Assert(WriteMatrixMarketAs(&buf, t.m, t.format, t.symmetry), check.IsNil, comment)
+		c.Check(buf.String(), check.Equals, t.want, comment)
+
+		back, e := ReadMatrixMarket(&buf)
+		c.Assert(e, check.IsNil, comment)
+		c.Check(Equal(back, t.m), check.Equals, true, comment)
+	}
+
+	for _, t := range []struct {
+		m                *Dense
+		format, symmetry string
+	}{
+		{sym, "array", "skew-symmetric"},
+		{skew, "coordinate", "symmetric"},
+		{NewDense(2, 3), "array", "symmetric"},
+		{sym, "sparse", "general"},
+		{sym, "array", "hermitian"},
+	} {
+		var buf bytes.Buffer
+		e := WriteMatrixMarketAs(&buf, t.m, t.format, t.symmetry)
+		c.Check(errors.Is(e, ErrFormat), check.Equals, true, check.Commentf("%s %s", t.format, t.symmetry))
+	}
+}
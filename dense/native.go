@@ -0,0 +1,501 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+)
+
+// engine lists the BLAS routines this package calls.
+// Any blas.Float64 satisfies it, so implementations passed to
+// Register are used as-is; native is the pure-Go fallback
+// that is in effect until Register is called.
+type engine interface {
+	Dgemm(o blas.Order, tA, tB blas.Transpose, m, n, k int,
+		alpha float64, a []float64, lda int, b []float64, ldb int,
+		beta float64, c []float64, ldc int)
+	Dsymm(o blas.Order, s blas.Side, ul blas.Uplo, m, n int,
+		alpha float64, a []float64, lda int, b []float64, ldb int,
+		beta float64, c []float64, ldc int)
+	Dsyrk(o blas.Order, ul blas.Uplo, t blas.Transpose, n, k int,
+		alpha float64, a []float64, lda int,
+		beta float64, c []float64, ldc int)
+	Dtrmm(o blas.Order, s blas.Side, ul blas.Uplo, tA blas.Transpose, d blas.Diag,
+		m, n int, alpha float64, a []float64, lda int, b []float64, ldb int)
+	Dtrsm(o blas.Order, s blas.Side, ul blas.Uplo, tA blas.Transpose, d blas.Diag,
+		m, n int, alpha float64, a []float64, lda int, b []float64, ldb int)
+}
+
+// native implements engine in pure Go, without cgo.
+// It is not as fast as an optimized CBLAS, but it
+// blocks the computation to make reasonable use of the cache.
+type native struct{}
+
+// Block sizes for the cache-blocked Dgemm.
+// A block of op(a) is blockM by blockK, a block of op(b) is
+// blockK by blockN; both fit comfortably in L2 cache.
+const (
+	blockM = 64
+	blockN = 256
+	blockK = 128
+)
+
+// Dgemm computes
+//    c = alpha * op(a) * op(b) + beta * c
+// where op(x) is x or x' depending on the transpose flag,
+// op(a) is m by k, op(b) is k by n, and c is m by n.
+func (e native) Dgemm(o blas.Order, tA, tB blas.Transpose, m, n, k int,
+	alpha float64, a []float64, lda int, b []float64, ldb int,
+	beta float64, c []float64, ldc int) {
+
+	if o == blas.ColMajor {
+		// A column-major c is the row-major c',
+		// and c' = op(b)' * op(a)'.
+		e.Dgemm(blas.RowMajor, tB, tA, n, m, k,
+			alpha, b, ldb, a, lda, beta, c, ldc)
+		return
+	}
+	if o != blas.RowMajor {
+		panic(ErrIllegalOrder)
+	}
+	if tA != blas.NoTrans && tA != blas.Trans {
+		panic(err("illegal transpose flag"))
+	}
+	if tB != blas.NoTrans && tB != blas.Trans {
+		panic(err("illegal transpose flag"))
+	}
+	if m < 0 || n < 0 || k < 0 {
+		panic(ErrIndexOutOfRange)
+	}
+	if m == 0 || n == 0 {
+		return
+	}
+
+	aTrans := tA == blas.Trans
+	bTrans := tB == blas.Trans
+	if aTrans {
+		check_ld(lda, m)
+	} else {
+		check_ld(lda, k)
+	}
+	if bTrans {
+		check_ld(ldb, k)
+	} else {
+		check_ld(ldb, n)
+	}
+	check_ld(ldc, n)
+
+	for i := 0; i < m; i++ {
+		row := c[i*ldc : i*ldc+n]
+		switch beta {
+		case 0:
+			zero(row)
+		case 1:
+		default:
+			scale(row, beta, row)
+		}
+	}
+
+	if alpha == 0 || k == 0 {
+		return
+	}
+
+	aPack := make([]float64, smaller(m, blockM)*smaller(k, blockK))
+	bPack := make([]float64, smaller(k, blockK)*smaller(n, blockN))
+
+	for jj := 0; jj < n; jj += blockN {
+		nb := smaller(blockN, n-jj)
+		for kk := 0; kk < k; kk += blockK {
+			kb := smaller(blockK, k-kk)
+
+			// Pack the kb by nb block of op(b) in row major.
+			for p := 0; p < kb; p++ {
+				packed := bPack[p*nb : (p+1)*nb]
+				if bTrans {
+					for j := range packed {
+						packed[j] = b[(jj+j)*ldb+kk+p]
+					}
+				} else {
+					copy(packed, b[(kk+p)*ldb+jj:])
+				}
+			}
+
+			for ii := 0; ii < m; ii += blockM {
+				mb := smaller(blockM, m-ii)
+
+				// Pack the mb by kb block of alpha * op(a) in row major.
+				for i := 0; i < mb; i++ {
+					packed := aPack[i*kb : (i+1)*kb]
+					if aTrans {
+						for p := range packed {
+							packed[p] = alpha * a[(kk+p)*lda+ii+i]
+						}
+					} else {
+						scale(a[(ii+i)*lda+kk:(ii+i)*lda+kk+kb], alpha, packed)
+					}
+				}
+
+				for i := 0; i < mb; i++ {
+					crow := c[(ii+i)*ldc+jj : (ii+i)*ldc+jj+nb]
+					for p, v := range aPack[i*kb : (i+1)*kb] {
+						if v == 0 {
+							continue
+						}
+						add_scaled(crow, bPack[p*nb:(p+1)*nb], v, crow)
+					}
+				}
+			}
+		}
+	}
+}
+
+// check_ld panics if the leading dimension ld is not valid
+// for a row-major matrix with n columns.
+func check_ld(ld, n int) {
+	if ld < larger(1, n) {
+		panic(ErrIllegalStride)
+	}
+}
+
+// flip_uplo returns the opposite triangle. The upper triangle of a
+// column-major matrix is the lower triangle of the row-major matrix
+// with the same data, and vice versa.
+func flip_uplo(ul blas.Uplo) blas.Uplo {
+	if ul == blas.Upper {
+		return blas.Lower
+	}
+	return blas.Upper
+}
+
+// sym_full returns an n by n full, row-major copy of the symmetric
+// matrix whose triangle ul is stored in a with leading dimension lda.
+func sym_full(ul blas.Uplo, n int, a []float64, lda int) []float64 {
+	full := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			var v float64
+			if ul == blas.Upper {
+				v = a[i*lda+j]
+			} else {
+				v = a[j*lda+i]
+			}
+			full[i*n+j] = v
+			full[j*n+i] = v
+		}
+	}
+	return full
+}
+
+// Dsymm computes
+//    c = alpha * a * b + beta * c    if s is blas.Left, or
+//    c = alpha * b * a + beta * c    if s is blas.Right,
+// where a is symmetric and only its triangle ul is referenced,
+// b and c are m by n.
+// The symmetric matrix is expanded and the product delegated to Dgemm.
+func (e native) Dsymm(o blas.Order, s blas.Side, ul blas.Uplo, m, n int,
+	alpha float64, a []float64, lda int, b []float64, ldb int,
+	beta float64, c []float64, ldc int) {
+
+	if o == blas.ColMajor {
+		// In row major, c' = alpha * b' * a + beta * c'
+		// with the triangles of a swapped.
+		side := blas.Left
+		if s == blas.Left {
+			side = blas.Right
+		}
+		e.Dsymm(blas.RowMajor, side, flip_uplo(ul), n, m,
+			alpha, a, lda, b, ldb, beta, c, ldc)
+		return
+	}
+	if o != blas.RowMajor {
+		panic(ErrIllegalOrder)
+	}
+	if ul != blas.Upper && ul != blas.Lower {
+		panic(err("illegal uplo flag"))
+	}
+
+	switch s {
+	case blas.Left:
+		check_ld(lda, m)
+		full := sym_full(ul, m, a, lda)
+		e.Dgemm(blas.RowMajor, blas.NoTrans, blas.NoTrans, m, n, m,
+			alpha, full, larger(m, 1), b, ldb, beta, c, ldc)
+	case blas.Right:
+		check_ld(lda, n)
+		full := sym_full(ul, n, a, lda)
+		e.Dgemm(blas.RowMajor, blas.NoTrans, blas.NoTrans, m, n, n,
+			alpha, b, ldb, full, larger(n, 1), beta, c, ldc)
+	default:
+		panic(err("illegal side flag"))
+	}
+}
+
+// Dsyrk computes
+//    c = alpha * a * a' + beta * c    if t is blas.NoTrans, or
+//    c = alpha * a' * a + beta * c    if t is blas.Trans,
+// where c is n by n symmetric, and only its triangle ul is referenced
+// and updated; a is n by k, or k by n if t is blas.Trans.
+// The product is delegated to Dgemm.
+func (e native) Dsyrk(o blas.Order, ul blas.Uplo, t blas.Transpose, n, k int,
+	alpha float64, a []float64, lda int,
+	beta float64, c []float64, ldc int) {
+
+	if o == blas.ColMajor {
+		// A column-major a is the row-major a'.
+		tt := blas.Trans
+		if t == blas.Trans {
+			tt = blas.NoTrans
+		}
+		e.Dsyrk(blas.RowMajor, flip_uplo(ul), tt, n, k,
+			alpha, a, lda, beta, c, ldc)
+		return
+	}
+	if o != blas.RowMajor {
+		panic(ErrIllegalOrder)
+	}
+	if ul != blas.Upper && ul != blas.Lower {
+		panic(err("illegal uplo flag"))
+	}
+	if n == 0 {
+		return
+	}
+	check_ld(ldc, n)
+
+	prod := make([]float64, n*n)
+	if t == blas.Trans {
+		e.Dgemm(blas.RowMajor, blas.Trans, blas.NoTrans, n, n, k,
+			alpha, a, lda, a, lda, 0, prod, n)
+	} else {
+		e.Dgemm(blas.RowMajor, blas.NoTrans, blas.Trans, n, n, k,
+			alpha, a, lda, a, lda, 0, prod, n)
+	}
+	for i := 0; i < n; i++ {
+		from, to := 0, i+1
+		if ul == blas.Upper {
+			from, to = i, n
+		}
+		row := c[i*ldc+from : i*ldc+to]
+		if beta == 0 {
+			copy(row, prod[i*n+from:i*n+to])
+		} else {
+			scale(row, beta, row)
+			add(row, prod[i*n+from:i*n+to], row)
+		}
+	}
+}
+
+// triangle describes op(a) for the triangular routines:
+// its elements, whether it is lower triangular,
+// and whether its diagonal is implicitly 1.
+type triangle struct {
+	a     []float64
+	lda   int
+	trans bool
+	lower bool
+	unit  bool
+}
+
+func new_triangle(ul blas.Uplo, tA blas.Transpose, d blas.Diag,
+	a []float64, lda int) triangle {
+
+	if ul != blas.Upper && ul != blas.Lower {
+		panic(err("illegal uplo flag"))
+	}
+	if tA != blas.NoTrans && tA != blas.Trans {
+		panic(err("illegal transpose flag"))
+	}
+	if d != blas.NonUnit && d != blas.Unit {
+		panic(err("illegal diag flag"))
+	}
+	trans := tA == blas.Trans
+	return triangle{a, lda, trans, (ul == blas.Lower) != trans, d == blas.Unit}
+}
+
+// at returns element (i, j) of op(a), which must be in the triangle.
+func (t triangle) at(i, j int) float64 {
+	if t.trans {
+		return t.a[j*t.lda+i]
+	}
+	return t.a[i*t.lda+j]
+}
+
+func (t triangle) diag(i int) float64 {
+	if t.unit {
+		return 1
+	}
+	return t.a[i*t.lda+i]
+}
+
+// Dtrsm solves
+//    op(a) * x = alpha * b    if s is blas.Left, or
+//    x * op(a) = alpha * b    if s is blas.Right,
+// for x, where a is triangular, and b is m by n and is overwritten by x.
+func (e native) Dtrsm(o blas.Order, s blas.Side, ul blas.Uplo, tA blas.Transpose, d blas.Diag,
+	m, n int, alpha float64, a []float64, lda int, b []float64, ldb int) {
+
+	if o == blas.ColMajor {
+		// In row major, x' * op(a)' = alpha * b' (or op(a)' * x' = alpha * b'),
+		// where a read in row major is a' with its triangles swapped.
+		e.Dtrsm(blas.RowMajor, flip_side(s), flip_uplo(ul), tA, d,
+			n, m, alpha, a, lda, b, ldb)
+		return
+	}
+	if o != blas.RowMajor {
+		panic(ErrIllegalOrder)
+	}
+	t := new_triangle(ul, tA, d, a, lda)
+	if m == 0 || n == 0 {
+		return
+	}
+	check_ld(ldb, n)
+
+	row := func(i int) []float64 { return b[i*ldb : i*ldb+n] }
+	if alpha != 1 {
+		for i := 0; i < m; i++ {
+			scale(row(i), alpha, row(i))
+		}
+	}
+
+	switch s {
+	case blas.Left:
+		// Substitute row by row.
+		check_ld(lda, m)
+		if t.lower {
+			for i := 0; i < m; i++ {
+				bi := row(i)
+				for k := 0; k < i; k++ {
+					if v := t.at(i, k); v != 0 {
+						add_scaled(bi, row(k), -v, bi)
+					}
+				}
+				if !t.unit {
+					scale(bi, 1/t.diag(i), bi)
+				}
+			}
+		} else {
+			for i := m - 1; i >= 0; i-- {
+				bi := row(i)
+				for k := i + 1; k < m; k++ {
+					if v := t.at(i, k); v != 0 {
+						add_scaled(bi, row(k), -v, bi)
+					}
+				}
+				if !t.unit {
+					scale(bi, 1/t.diag(i), bi)
+				}
+			}
+		}
+	case blas.Right:
+		// Each row x of the solution satisfies x * op(a) = b.
+		check_ld(lda, n)
+		for i := 0; i < m; i++ {
+			x := row(i)
+			if t.lower {
+				for j := n - 1; j >= 0; j-- {
+					if !t.unit {
+						x[j] /= t.diag(j)
+					}
+					for k := 0; k < j; k++ {
+						x[k] -= x[j] * t.at(j, k)
+					}
+				}
+			} else {
+				for j := 0; j < n; j++ {
+					if !t.unit {
+						x[j] /= t.diag(j)
+					}
+					for k := j + 1; k < n; k++ {
+						x[k] -= x[j] * t.at(j, k)
+					}
+				}
+			}
+		}
+	default:
+		panic(err("illegal side flag"))
+	}
+}
+
+// Dtrmm computes
+//    b = alpha * op(a) * b    if s is blas.Left, or
+//    b = alpha * b * op(a)    if s is blas.Right,
+// where a is triangular, and b is m by n.
+func (e native) Dtrmm(o blas.Order, s blas.Side, ul blas.Uplo, tA blas.Transpose, d blas.Diag,
+	m, n int, alpha float64, a []float64, lda int, b []float64, ldb int) {
+
+	if o == blas.ColMajor {
+		e.Dtrmm(blas.RowMajor, flip_side(s), flip_uplo(ul), tA, d,
+			n, m, alpha, a, lda, b, ldb)
+		return
+	}
+	if o != blas.RowMajor {
+		panic(ErrIllegalOrder)
+	}
+	t := new_triangle(ul, tA, d, a, lda)
+	if m == 0 || n == 0 {
+		return
+	}
+	check_ld(ldb, n)
+
+	row := func(i int) []float64 { return b[i*ldb : i*ldb+n] }
+
+	switch s {
+	case blas.Left:
+		// Row i of the product combines rows of b that are
+		// on or below row i for upper op(a), on or above for lower;
+		// visit the rows in the order that leaves those intact.
+		check_ld(lda, m)
+		update := func(i, from, to int) {
+			bi := row(i)
+			scale(bi, t.diag(i), bi)
+			for k := from; k < to; k++ {
+				if v := t.at(i, k); v != 0 {
+					add_scaled(bi, row(k), v, bi)
+				}
+			}
+		}
+		if t.lower {
+			for i := m - 1; i >= 0; i-- {
+				update(i, 0, i)
+			}
+		} else {
+			for i := 0; i < m; i++ {
+				update(i, i+1, m)
+			}
+		}
+	case blas.Right:
+		check_ld(lda, n)
+		for i := 0; i < m; i++ {
+			x := row(i)
+			if t.lower {
+				for j := 0; j < n; j++ {
+					v := x[j] * t.diag(j)
+					for k := j + 1; k < n; k++ {
+						v += x[k] * t.at(k, j)
+					}
+					x[j] = v
+				}
+			} else {
+				for j := n - 1; j >= 0; j-- {
+					v := x[j] * t.diag(j)
+					for k := 0; k < j; k++ {
+						v += x[k] * t.at(k, j)
+					}
+					x[j] = v
+				}
+			}
+		}
+	default:
+		panic(err("illegal side flag"))
+	}
+
+	if alpha != 1 {
+		for i := 0; i < m; i++ {
+			scale(row(i), alpha, row(i))
+		}
+	}
+}
+
+func flip_side(s blas.Side) blas.Side {
+	if s == blas.Left {
+		return blas.Right
+	}
+	return blas.Left
+}
@@ -0,0 +1,111 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+	check "launchpad.net/gocheck"
+	"math/rand"
+)
+
+// naive_gemm is the textbook triple loop, used as a reference.
+func naive_gemm(tA, tB bool, alpha float64, a, b *Dense, beta float64, c *Dense) {
+	at, bt := a, b
+	if tA {
+		at = T(a, nil)
+	}
+	if tB {
+		bt = T(b, nil)
+	}
+	for i := 0; i < c.rows; i++ {
+		for j := 0; j < c.cols; j++ {
+			v := 0.0
+			for k := 0; k < at.cols; k++ {
+				v += at.Get(i, k) * bt.Get(k, j)
+			}
+			c.Set(i, j, alpha*v+beta*c.Get(i, j))
+		}
+	}
+}
+
+func rand_dense(r, c int) *Dense {
+	m := NewDense(r, c)
+	for i := range m.data {
+		m.data[i] = rand.NormFloat64()
+	}
+	return m
+}
+
+func (s *S) TestNativeDgemm(c *check.C) {
+	flag := func(t bool) blas.Transpose {
+		if t {
+			return blas.Trans
+		}
+		return blas.NoTrans
+	}
+	// Sizes straddle the block boundaries.
+	for _, dims := range [][3]int{
+		{1, 1, 1}, {3, 4, 5}, {70, 30, 140}, {65, 300, 129},
+	} {
+		m, n, k := dims[0], dims[1], dims[2]
+		for _, tA := range []bool{false, true} {
+			for _, tB := range []bool{false, true} {
+				var a, b *Dense
+				if tA {
+					a = rand_dense(k, m)
+				} else {
+					a = rand_dense(m, k)
+				}
+				if tB {
+					b = rand_dense(n, k)
+				} else {
+					b = rand_dense(k, n)
+				}
+				c0 := rand_dense(m, n)
+				want := Clone(c0)
+				naive_gemm(tA, tB, 1.5, a, b, -0.5, want)
+
+				got := Clone(c0)
+				native{}.Dgemm(blas.RowMajor, flag(tA), flag(tB), m, n, k,
+					1.5, a.data, a.stride, b.data, b.stride,
+					-0.5, got.data, got.stride)
+				c.Check(Approx(got, want, 1e-10), check.Equals, true,
+					check.Commentf("row major %v, tA %v, tB %v", dims, tA, tB))
+
+				// The same product in column major: the transposed
+				// row-major data, with swapped dimensions.
+				got = T(c0, nil)
+				native{}.Dgemm(blas.ColMajor, flag(tA), flag(tB), m, n, k,
+					1.5, T(a, nil).data, a.rows, T(b, nil).data, b.rows,
+					-0.5, got.data, m)
+				c.Check(Approx(T(got, nil), want, 1e-10), check.Equals, true,
+					check.Commentf("col major %v, tA %v, tB %v", dims, tA, tB))
+			}
+		}
+	}
+}
+
+func (s *S) TestNativeDgemmSubmatrix(c *check.C) {
+	a := rand_dense(10, 12)
+	b := rand_dense(9, 11)
+	out := rand_dense(8, 8)
+	x := a.SubmatrixView(2, 3, 4, 5)
+	y := b.SubmatrixView(1, 2, 5, 6)
+	z := out.SubmatrixView(3, 1, 4, 6)
+	orig := Clone(out)
+
+	want := NewDense(4, 6)
+	naive_gemm(false, false, 1, x, y, 0, want)
+	Mult(x, y, z)
+
+	c.Check(Approx(z, want, 1e-12), check.Equals, true)
+	// Elements of out outside of z are untouched.
+	Copy(z, orig.SubmatrixView(3, 1, 4, 6))
+	c.Check(Equal(out, orig), check.Equals, true)
+}
+
+func (s *S) TestRegister(c *check.C) {
+	_, ok := blasEngine.(native)
+	c.Check(ok, check.Equals, true)
+	Register(nil)
+	_, ok = blasEngine.(native)
+	c.Check(ok, check.Equals, true)
+}
@@ -0,0 +1,179 @@
+package dense
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Support for the NumPy .npy and .npz file formats, see
+// http://docs.scipy.org/doc/numpy/neps/npy-format.html
+
+const npyMagic = "\x93NUMPY"
+
+var (
+	npyDescr   = regexp.MustCompile(`['"]descr['"]\s*:\s*['"]([^'"]*)['"]`)
+	npyFortran = regexp.MustCompile(`['"]fortran_order['"]\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`['"]shape['"]\s*:\s*\(([^)]*)\)`)
+)
+
+// ReadNpy reads a 2-dimensional array of little-endian float64
+// (dtype '<f8') in NumPy .npy format from r.
+// Arrays in both C and Fortran order are accepted;
+// the latter are transposed into the row-major layout of Dense.
+//
+// Errors in the input are reported as errors wrapping ErrFormat.
+func ReadNpy(r io.Reader) (*Dense, error) {
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: npy: %s", ErrFormat, fmt.Sprintf(format, args...))
+	}
+
+	var pre [8]byte
+	if _, e := io.ReadFull(r, pre[:]); e != nil {
+		return nil, fail("short header")
+	}
+	if string(pre[:6]) != npyMagic {
+		return nil, fail("bad magic string")
+	}
+	var hlen int
+	switch pre[6] {
+	case 1:
+		var b [2]byte
+		if _, e := io.ReadFull(r, b[:]); e != nil {
+			return nil, fail("short header")
+		}
+		hlen = int(binary.LittleEndian.Uint16(b[:]))
+	case 2, 3:
+		var b [4]byte
+		if _, e := io.ReadFull(r, b[:]); e != nil {
+			return nil, fail("short header")
+		}
+		hlen = int(binary.LittleEndian.Uint32(b[:]))
+	default:
+		return nil, fail("unsupported version %d.%d", pre[6], pre[7])
+	}
+	hbuf := make([]byte, hlen)
+	if _, e := io.ReadFull(r, hbuf); e != nil {
+		return nil, fail("short header")
+	}
+	header := string(hbuf)
+
+	descr := npyDescr.FindStringSubmatch(header)
+	fortran := npyFortran.FindStringSubmatch(header)
+	shape := npyShape.FindStringSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return nil, fail("bad header %q", header)
+	}
+	if descr[1] != "<f8" {
+		return nil, fail("unsupported dtype %q", descr[1])
+	}
+	var dims []int
+	for _, s := range strings.Split(shape[1], ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		d, e := strconv.Atoi(strings.TrimSuffix(s, "L"))
+		if e != nil || d < 0 {
+			return nil, fail("bad shape %q", shape[1])
+		}
+		dims = append(dims, d)
+	}
+	if len(dims) != 2 {
+		return nil, fail("array is %d-dimensional, expect 2", len(dims))
+	}
+
+	rows, cols := dims[0], dims[1]
+	m := NewDense(rows, cols)
+	buf := make([]byte, 8*larger(rows, cols))
+	if rows == 0 || cols == 0 {
+		return m, nil
+	}
+	if fortran[1] == "True" {
+		col := make([]float64, rows)
+		for j := 0; j < cols; j++ {
+			if e := read_float64s(r, buf, col); e != nil {
+				return nil, fail("short data")
+			}
+			m.SetCol(j, col)
+		}
+	} else {
+		for i := 0; i < rows; i++ {
+			if e := read_float64s(r, buf, m.RowView(i)); e != nil {
+				return nil, fail("short data")
+			}
+		}
+	}
+	return m, nil
+}
+
+// read_float64s fills x with little-endian float64 values read from r,
+// using buf, which must be large enough, as scratch space.
+func read_float64s(r io.Reader, buf []byte, x []float64) error {
+	buf = buf[:8*len(x)]
+	if _, e := io.ReadFull(r, buf); e != nil {
+		return e
+	}
+	for i := range x {
+		x[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return nil
+}
+
+// WriteNpy writes m to w in NumPy .npy format (version 1.0),
+// as a C-order array of dtype '<f8'.
+// m may be a submatrix view; only its own elements are written.
+func WriteNpy(w io.Writer, m *Dense) error {
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }",
+		m.rows, m.cols)
+	// Pad with spaces and a newline so that the data starts
+	// at a multiple of 64 bytes.
+	n := len(npyMagic) + 4 + len(header) + 1
+	header += strings.Repeat(" ", (64-n%64)%64) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(npyMagic)
+	bw.Write([]byte{1, 0})
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+
+	buf := make([]byte, 8*m.cols)
+	for i := 0; i < m.rows; i++ {
+		for j, v := range m.RowView(i) {
+			binary.LittleEndian.PutUint64(buf[8*j:], math.Float64bits(v))
+		}
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
+
+// ReadNpz reads the arrays in a NumPy .npz archive of the given size
+// from r, and returns them keyed by name, e.g. "arr_0" or the keyword
+// used in numpy.savez. Both plain and compressed archives are
+// accepted. Every array must be acceptable to ReadNpy.
+func ReadNpz(r io.ReaderAt, size int64) (map[string]*Dense, error) {
+	z, e := zip.NewReader(r, size)
+	if e != nil {
+		return nil, fmt.Errorf("%w: npz: %v", ErrFormat, e)
+	}
+	out := make(map[string]*Dense, len(z.File))
+	for _, f := range z.File {
+		rc, e := f.Open()
+		if e != nil {
+			return nil, e
+		}
+		m, e := ReadNpy(rc)
+		rc.Close()
+		if e != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, e)
+		}
+		out[strings.TrimSuffix(f.Name, ".npy")] = m
+	}
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package dense
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	check "launchpad.net/gocheck"
+	"math"
+	"strings"
+)
+
+// npy_bytes assembles a version 1.0 .npy file with the given
+// header dictionary and data, padded the way numpy does it.
+func npy_bytes(dict string, data []float64) []byte {
+	n := 10 + len(dict) + 1
+	dict += strings.Repeat(" ", (64-n%64)%64) + "\n"
+	var b bytes.Buffer
+	b.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&b, binary.LittleEndian, uint16(len(dict)))
+	b.WriteString(dict)
+	for _, v := range data {
+		binary.Write(&b, binary.LittleEndian, math.Float64bits(v))
+	}
+	return b.Bytes()
+}
+
+func (s *S) TestReadNpy(c *check.C) {
+	want := flatten2dense([][]float64{{1, 2, 3}, {4, 5, 6}})
+
+	in := npy_bytes("{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }",
+		[]float64{1, 2, 3, 4, 5, 6})
+	m, e := ReadNpy(bytes.NewReader(in))
+	c.Assert(e, check.IsNil)
+	c.Check(Equal(m, want), check.Equals, true)
+
+	in = npy_bytes("{'descr': '<f8', 'fortran_order': True, 'shape': (2, 3), }",
+		[]float64{1, 4, 2, 5, 3, 6})
+	m, e = ReadNpy(bytes.NewReader(in))
+	c.Assert(e, check.IsNil)
+	c.Check(Equal(m, want), check.Equals, true)
+
+	// Empty arrays, in both orders.
+	for _, shape := range [][2]int{{0, 3}, {3, 0}, {0, 0}} {
+		for _, order := range []string{"False", "True"} {
+			in = npy_bytes(fmt.Sprintf("{'descr': '<f8', 'fortran_order': %s, 'shape': (%d, %d), }",
+				order, shape[0], shape[1]), nil)
+			m, e = ReadNpy(bytes.NewReader(in))
+			c.Assert(e, check.IsNil, check.Commentf("%v %s", shape, order))
+			r, cc := m.Dims()
+			c.Check([2]int{r, cc}, check.Equals, shape)
+		}
+	}
+
+	for _, in := range [][]byte{
+		[]byte("\x93NUMPX\x01\x00"),
+		npy_bytes("{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }",
+			[]float64{1, 2, 3}),
+		npy_bytes("{'descr': '<f8', 'fortran_order': False, 'shape': (6,), }",
+			[]float64{1, 2, 3, 4, 5, 6}),
+		npy_bytes("{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }",
+			[]float64{1, 2, 3, 4, 5}),
+	} {
+		_, e := ReadNpy(bytes.NewReader(in))
+		c.Check(errors.Is(e, ErrFormat), check.Equals, true, check.Commentf("%v", e))
+	}
+}
+
+func (s *S) TestWriteNpy(c *check.C) {
+	m := rand_dense(5, 7)
+	sub := m.SubmatrixView(1, 2, 3, 4)
+
+	var b bytes.Buffer
+	c.Assert(WriteNpy(&b, sub), check.IsNil)
+	c.Check(b.Len(), check.Equals, 128+8*12)
+	c.Check(b.Bytes()[127], check.Equals, byte('\n'))
+
+	back, e := ReadNpy(&b)
+	c.Assert(e, check.IsNil)
+	c.Check(Equal(back, sub), check.Equals, true)
+}
+
+func (s *S) TestReadNpz(c *check.C) {
+	x := rand_dense(3, 2)
+	y := rand_dense(1, 4)
+
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for name, m := range map[string]*Dense{"x": x, "arr_0": y} {
+		w, e := z.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Deflate})
+		c.Assert(e, check.IsNil)
+		c.Assert(WriteNpy(w, m), check.IsNil)
+	}
+	c.Assert(z.Close(), check.IsNil)
+
+	ms, e := ReadNpz(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	c.Assert(e, check.IsNil)
+	c.Check(len(ms), check.Equals, 2)
+	c.Check(Equal(ms["x"], x), check.Equals, true)
+	c.Check(Equal(ms["arr_0"], y), check.Equals, true)
+
+	_, e = ReadNpz(bytes.NewReader([]byte("not a zip")), 9)
+	c.Check(errors.Is(e, ErrFormat), check.Equals, true)
+}
@@ -0,0 +1,115 @@
+package dense
+
+// Matrices created by SubmatrixView, RowView, DenseView and the like
+// may share storage. Functions that write into an output matrix
+// use the helpers in this file to detect when the output overlaps
+// an input, and work on a temporary copy in that case.
+//
+// Two slices share storage only if they are slices of the same
+// underlying array. A slice of an array keeps the capacity up to the
+// end of the array, unless it was created by a full slice expression,
+// hence two slices share the array if the last elements within their
+// capacities are the same element. The distance to that element gives
+// the offset of the slice within the array.
+
+// offset returns the position of the first element of x
+// relative to the first element of y, and whether x and y are
+// slices of the same underlying array.
+func offset(x, y []float64) (int, bool) {
+	if cap(x) == 0 || cap(y) == 0 {
+		return 0, false
+	}
+	if &x[:cap(x)][cap(x)-1] != &y[:cap(y)][cap(y)-1] {
+		return 0, false
+	}
+	return cap(y) - cap(x), true
+}
+
+// overlap reports whether a and b share any element.
+func overlap(a, b *Dense) bool {
+	if a.rows == 0 || a.cols == 0 || b.rows == 0 || b.cols == 0 {
+		return false
+	}
+	d, ok := offset(b.data, a.data)
+	if !ok {
+		return false
+	}
+	if a.stride != b.stride {
+		// Compare the spans of the elements.
+		return d < (a.rows-1)*a.stride+a.cols &&
+			-d < (b.rows-1)*b.stride+b.cols
+	}
+
+	// With a common stride s, both are rectangles on a grid of
+	// width s. Put a at row 0, col 0; b then starts at row q, col r.
+	s := a.stride
+	q, r := d/s, d%s
+	if r < 0 {
+		q, r = q-1, r+s
+	}
+	// b occupies cols [r, r+b.cols) on rows q through q+b.rows-1,
+	// except that cols beyond s wrap around to the next row.
+	if rect_overlap(0, a.rows, 0, a.cols,
+		q, q+b.rows, r, smaller(r+b.cols, s)) {
+		return true
+	}
+	if r+b.cols > s {
+		return rect_overlap(0, a.rows, 0, a.cols,
+			q+1, q+1+b.rows, 0, r+b.cols-s)
+	}
+	return false
+}
+
+// rect_overlap reports whether the rectangles of rows [r1, r2)
+// and cols [c1, c2), and rows [s1, s2) and cols [d1, d2) intersect.
+func rect_overlap(r1, r2, c1, c2, s1, s2, d1, d2 int) bool {
+	return r1 < s2 && s1 < r2 && c1 < d2 && d1 < c2
+}
+
+// same reports whether a and b are views of the very same elements,
+// laid out in the same way.
+func same(a, b *Dense) bool {
+	if a.rows != b.rows || a.cols != b.cols || a.stride != b.stride {
+		return false
+	}
+	d, ok := offset(a.data, b.data)
+	return ok && d == 0
+}
+
+// unalias returns m, or a copy of m if m shares storage with out
+// but is not out itself.
+// It serves operations that work element by element,
+// for which out being one of the inputs is fine but a partial
+// overlap would read elements that have been overwritten.
+func unalias(out, m *Dense) *Dense {
+	if overlap(out, m) && !same(out, m) {
+		return Clone(m)
+	}
+	return m
+}
+
+// scratch_out returns c if it does not overlap any of ins, and a newly
+// allocated matrix of the same shape otherwise, into which the elements
+// of c are copied if keep is true. It serves BLAS routines whose output
+// must be distinct from their inputs. Once the output has been written,
+// pass it to flush_out.
+func scratch_out(c *Dense, keep bool, ins ...*Dense) *Dense {
+	for _, in := range ins {
+		if overlap(c, in) {
+			out := NewDense(c.rows, c.cols)
+			if keep {
+				Copy(out, c)
+			}
+			return out
+		}
+	}
+	return c
+}
+
+// flush_out copies out into c if out is a scratch matrix
+// returned by scratch_out.
+func flush_out(c, out *Dense) {
+	if out != c {
+		Copy(c, out)
+	}
+}
@@ -0,0 +1,142 @@
+package dense
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestOverlap(c *check.C) {
+	m := NewDense(6, 8)
+	for _, test := range []struct {
+		a, b *Dense
+		want bool
+	}{
+		{m, m, true},
+		{m, NewDense(6, 8), false},
+		{m, m.SubmatrixView(2, 3, 2, 2), true},
+		// Side by side, on the same rows.
+		{m.SubmatrixView(0, 0, 6, 4), m.SubmatrixView(0, 4, 6, 4), false},
+		{m.SubmatrixView(0, 0, 6, 5), m.SubmatrixView(0, 4, 6, 4), true},
+		// One above the other.
+		{m.SubmatrixView(0, 0, 3, 8), m.SubmatrixView(3, 0, 3, 8), false},
+		{m.SubmatrixView(0, 2, 4, 3), m.SubmatrixView(3, 4, 3, 3), true},
+		// Diagonal neighbours.
+		{m.SubmatrixView(0, 0, 3, 3), m.SubmatrixView(3, 3, 3, 3), false},
+		{m.SubmatrixView(3, 0, 3, 3), m.SubmatrixView(0, 3, 3, 3), false},
+		// Row views.
+		{DenseView(m.RowView(2), 1, 8), m.SubmatrixView(2, 7, 1, 1), true},
+		{DenseView(m.RowView(2), 2, 4), m.SubmatrixView(3, 0, 2, 2), false},
+		{DenseView(m.RowView(2), 2, 4), m.SubmatrixView(1, 5, 2, 2), true},
+	} {
+		c.Check(overlap(test.a, test.b), check.Equals, test.want,
+			check.Commentf("%v %v", test.a, test.b))
+		c.Check(overlap(test.b, test.a), check.Equals, test.want)
+	}
+}
+
+func (s *S) TestMultAliased(c *check.C) {
+	a := rand_dense(4, 4)
+	b := rand_dense(4, 4)
+
+	// Full overlap.
+	want := Mult(a, b, nil)
+	Mult(a, b, a)
+	c.Check(Approx(a, want, 1e-12), check.Equals, true)
+
+	want = Mult(a, b, nil)
+	Mult(a, b, b)
+	c.Check(Approx(b, want, 1e-12), check.Equals, true)
+
+	want = Mult(a.TView(), a, nil)
+	Mult(a.TView(), a, a)
+	c.Check(Approx(a, want, 1e-12), check.Equals, true)
+
+	// Partial overlap of views.
+	m := rand_dense(6, 6)
+	x := m.SubmatrixView(0, 0, 4, 3)
+	y := m.SubmatrixView(1, 2, 3, 4)
+	out := m.SubmatrixView(2, 1, 4, 4)
+	want = Mult(Clone(x), Clone(y), nil)
+	Mult(x, y, out)
+	c.Check(Approx(out, want, 1e-12), check.Equals, true)
+
+	// Gemm with beta reads the original out.
+	m = rand_dense(6, 6)
+	x = m.SubmatrixView(0, 0, 3, 3)
+	out = m.SubmatrixView(1, 1, 3, 3)
+	want = Clone(out)
+	naive_gemm(false, false, 2, Clone(x), Clone(x), 3, want)
+	Gemm(false, false, 2, x, x, 3, out)
+	c.Check(Approx(out, want, 1e-12), check.Equals, true)
+}
+
+func (s *S) TestCopyAliased(c *check.C) {
+	m := flatten2dense([][]float64{
+		{1, 2, 3, 4},
+		{5, 6, 7, 8},
+		{9, 10, 11, 12},
+	})
+	Copy(m.SubmatrixView(1, 1, 2, 3), m.SubmatrixView(0, 0, 2, 3))
+	c.Check(Equal(m, flatten2dense([][]float64{
+		{1, 2, 3, 4},
+		{5, 1, 2, 3},
+		{9, 5, 6, 7},
+	})), check.Equals, true)
+
+	Copy(m, m)
+	c.Check(m.Get(2, 3), check.Equals, 7.0)
+
+	sq := m.SubmatrixView(0, 0, 3, 3)
+	Copy(sq, sq.TView())
+	c.Check(Equal(sq, flatten2dense([][]float64{
+		{1, 5, 9},
+		{2, 1, 5},
+		{3, 2, 6},
+	})), check.Equals, true)
+}
+
+func (s *S) TestTAliased(c *check.C) {
+	m := flatten2dense([][]float64{
+		{1, 2, 3, 4},
+		{5, 6, 7, 8},
+		{9, 10, 11, 12},
+	})
+	src := Clone(m.SubmatrixView(0, 0, 2, 3))
+	T(m.SubmatrixView(0, 0, 2, 3), m.SubmatrixView(0, 1, 3, 2))
+	c.Check(Equal(m.SubmatrixView(0, 1, 3, 2), T(src, nil)), check.Equals, true)
+
+	sq := m.SubmatrixView(0, 0, 3, 3)
+	want := T(sq, nil)
+	T(sq, sq)
+	c.Check(Equal(sq, want), check.Equals, true)
+}
+
+func (s *S) TestStackAliased(c *check.C) {
+	m := rand_dense(4, 6)
+	a := Clone(m.SubmatrixView(0, 0, 4, 2))
+	b := Clone(m.SubmatrixView(0, 1, 4, 3))
+	Hstack(m.SubmatrixView(0, 0, 4, 2), m.SubmatrixView(0, 1, 4, 3),
+		m.SubmatrixView(0, 1, 4, 5))
+	c.Check(Equal(m.SubmatrixView(0, 1, 4, 2), a), check.Equals, true)
+	c.Check(Equal(m.SubmatrixView(0, 3, 4, 3), b), check.Equals, true)
+
+	m = rand_dense(6, 3)
+	a = Clone(m.SubmatrixView(0, 0, 2, 3))
+	b = Clone(m.SubmatrixView(1, 0, 3, 3))
+	Vstack(m.SubmatrixView(0, 0, 2, 3), m.SubmatrixView(1, 0, 3, 3),
+		m.SubmatrixView(1, 0, 5, 3))
+	c.Check(Equal(m.SubmatrixView(1, 0, 2, 3), a), check.Equals, true)
+	c.Check(Equal(m.SubmatrixView(3, 0, 3, 3), b), check.Equals, true)
+}
+
+func (s *S) TestElementWiseAliased(c *check.C) {
+	m := flatten2dense([][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	})
+	x := m.SubmatrixView(0, 0, 2, 2)
+	y := m.SubmatrixView(1, 1, 2, 2)
+	want := Add(Clone(x), Clone(y), nil)
+	Add(x, y, m.SubmatrixView(0, 1, 2, 2))
+	c.Check(Equal(m.SubmatrixView(0, 1, 2, 2), want), check.Equals, true)
+}
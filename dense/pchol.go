@@ -0,0 +1,150 @@
+package dense
+
+import (
+	"math"
+
+	"github.com/gonum/blas"
+)
+
+// PivotedCholFactors contains the pivoted Cholesky factorization
+//    P * M * P' ~ L * L'
+// of a symmetric positive semidefinite matrix M, where L is n by r
+// lower trapezoidal, r is the numerical rank of M, and P is the
+// permutation that brings the largest remaining diagonal element to the
+// front at each step. P' * L is then a rank r factor of M, e.g. for a
+// Nystrom approximation of a kernel matrix.
+type PivotedCholFactors struct {
+	l    *Dense // n by r
+	perm []int  // row k of P * M is row perm[k] of M
+}
+
+// PivotedChol computes the pivoted Cholesky factorization of M, which is
+// only read, in its lower triangle. It stops when all remaining diagonal
+// elements are at most tol; a negative tol selects n * eps * max(diag(M)),
+// as LAPACK DPSTRF does. The success flag is false if M is not square.
+//
+// The work is O(n^2 * r), with only the first r cols of L computed.
+func PivotedChol(M Matrix, tol float64) (*PivotedCholFactors, bool) {
+	n, c := M.Dims()
+	if c != n {
+		return nil, false
+	}
+
+	a := NewDense(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			v := M.At(i, j)
+			a.Set(i, j, v)
+			a.Set(j, i, v)
+		}
+	}
+	perm := make([]int, n)
+	d := make([]float64, n) // diagonal of the remaining matrix, in P order
+	dmax := 0.0
+	for i := range perm {
+		perm[i] = i
+		d[i] = a.Get(i, i)
+		dmax = math.Max(dmax, d[i])
+	}
+	if tol < 0 {
+		tol = float64(n) * 2.2204e-16 * dmax
+	}
+
+	l := NewDense(n, n)
+	r := 0
+	for ; r < n; r++ {
+		// Pivot.
+		p := r
+		for i := r + 1; i < n; i++ {
+			if d[i] > d[p] {
+				p = i
+			}
+		}
+		if d[p] <= tol {
+			break
+		}
+		if p != r {
+			swap(l.RowView(p)[:r], l.RowView(r)[:r])
+			perm[p], perm[r] = perm[r], perm[p]
+			d[p], d[r] = d[r], d[p]
+		}
+
+		// Col r of L.
+		lrr := math.Sqrt(d[r])
+		l.Set(r, r, lrr)
+		lRowr := l.RowView(r)
+		for i := r + 1; i < n; i++ {
+			lRowi := l.RowView(i)
+			v := (a.Get(perm[i], perm[r]) - dot(lRowi[:r], lRowr[:r])) / lrr
+			lRowi[r] = v
+			d[i] -= v * v
+		}
+	}
+
+	if r == 0 {
+		return &PivotedCholFactors{NewDense(n, 0), perm}, true
+	}
+	return &PivotedCholFactors{l.SubmatrixView(0, 0, n, r), perm}, true
+}
+
+// Rank returns the numerical rank r of M, the number of cols of L.
+func (f *PivotedCholFactors) Rank() int {
+	return f.l.Cols()
+}
+
+// L returns the n by r factor L, whose leading r by r block is lower
+// triangular. Since the returned matrix is a view of internal data of
+// f, one is not expected to make changes to it.
+func (f *PivotedCholFactors) L() *Dense {
+	return f.l
+}
+
+// Perm returns the permutation P as a slice p, where row k of P * M
+// is row p[k] of M.
+func (f *PivotedCholFactors) Perm() []int {
+	return append([]int(nil), f.perm...)
+}
+
+// Factor returns P' * L in a new matrix g, so that M ~ g * g'.
+func (f *PivotedCholFactors) Factor() *Dense {
+	n, r := f.l.Dims()
+	g := NewDense(n, r)
+	for k, i := range f.perm {
+		g.SetRow(i, f.l.RowView(k))
+	}
+	return g
+}
+
+// SolveReg returns a matrix x that solves (M + lambda * I) * x = b, with
+// M replaced by its rank r approximation, for lambda > 0. By the
+// Woodbury identity, this takes only a Cholesky factorization of the r
+// by r matrix lambda * I + L' * L.
+// The matrix b must have the same number of rows as M.
+// b is overwritten by the operation and returned containing the
+// solution.
+func (f *PivotedCholFactors) SolveReg(lambda float64, b *Dense) *Dense {
+	return must_dense(f.TrySolveReg(lambda, b))
+}
+
+// TrySolveReg is SolveReg returning an error instead of panicking.
+// The error is ErrNotPosDef if lambda is not positive.
+func (f *PivotedCholFactors) TrySolveReg(lambda float64, b *Dense) (*Dense, error) {
+	n, r := f.l.Dims()
+	if b.Rows() != n {
+		return nil, shape_error("PivotedCholFactors.SolveReg", ErrShapes, f.l, b)
+	}
+	if lambda <= 0 {
+		return nil, ErrNotPosDef
+	}
+
+	// x = (b - g * (lambda * I + g' * g)^-1 * g' * b) / lambda
+	b.Scale(1 / lambda)
+	if r == 0 {
+		return b, nil
+	}
+	g := f.Factor()
+	s := SymDenseFrom(eye(r).Scale(lambda), blas.Lower, false).SymRankK(1, g.TView())
+	ch, _ := Chol(s)
+	y := ch.Solve(Mult(g.TView(), b, nil))
+	return Gemm(false, false, -1, g, y, 1, b), nil
+}
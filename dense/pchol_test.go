@@ -0,0 +1,67 @@
+package dense
+
+import (
+	"math/rand"
+
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestPivotedChol(c *check.C) {
+	// A positive semidefinite matrix of rank 5.
+	g, _ := randDense(30, 1, rand.NormFloat64)
+	g = g.SubmatrixView(0, 0, 30, 5)
+	a := Mult(g, g.TView(), nil)
+
+	f, ok := PivotedChol(a, -1)
+	c.Assert(ok, check.Equals, true)
+	c.Check(f.Rank(), check.Equals, 5)
+	l := f.L()
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			c.Check(l.Get(i, j), check.Equals, 0.0)
+		}
+	}
+	pa := NewDense(30, 30)
+	for i, pi := range f.Perm() {
+		for j, pj := range f.Perm() {
+			pa.Set(i, j, a.Get(pi, pj))
+		}
+	}
+	c.Check(Approx(Mult(l, l.TView(), nil), pa, 1e-10), check.Equals, true)
+	fg := f.Factor()
+	c.Check(Approx(Mult(fg, fg.TView(), nil), a, 1e-10), check.Equals, true)
+
+	// The diagonal of L does not increase.
+	for i := 1; i < 5; i++ {
+		c.Check(l.Get(i, i) <= l.Get(i-1, i-1), check.Equals, true)
+	}
+
+	// Regularized solve.
+	b, _ := randDense(30, 1, rand.NormFloat64)
+	b = b.SubmatrixView(0, 0, 30, 3)
+	x := f.SolveReg(0.5, Clone(b))
+	want := Solve(Add(Clone(a), eye(30).Scale(0.5), nil), Clone(b))
+	c.Check(Approx(x, want, 1e-9), check.Equals, true)
+	_, e := f.TrySolveReg(0, Clone(b))
+	c.Check(e, check.Equals, ErrNotPosDef)
+
+	// A coarse tolerance drops the small directions.
+	for i := 0; i < 30; i++ {
+		g.Set(i, 3, 1e-4*g.Get(i, 3))
+		g.Set(i, 4, 1e-4*g.Get(i, 4))
+	}
+	f, _ = PivotedChol(Mult(g, g.TView(), nil), 1e-5)
+	c.Check(f.Rank(), check.Equals, 3)
+
+	// Positive definite: full rank, as Chol up to the permutation.
+	spd := rand_spd(8)
+	f, _ = PivotedChol(spd, -1)
+	c.Check(f.Rank(), check.Equals, 8)
+
+	f, _ = PivotedChol(NewDense(3, 3), -1)
+	c.Check(f.Rank(), check.Equals, 0)
+	c.Check(Approx(f.SolveReg(2, eye(3)), eye(3).Scale(0.5), 1e-15), check.Equals, true)
+
+	_, ok = PivotedChol(NewDense(2, 3), -1)
+	c.Check(ok, check.Equals, false)
+}
@@ -7,6 +7,8 @@ package dense
 
 import (
 	"math"
+
+	"github.com/gonum/blas"
 )
 
 type QRFactor struct {
@@ -17,21 +19,31 @@ type QRFactor struct {
 // QR computes a QR Decomposition for an m-by-n matrix a with m >= n by Householder
 // reflections, the QR decomposition is an m-by-n orthogonal matrix q and an n-by-n
 // upper triangular matrix r so that a = q.r. QR will panic with
-// errInShape if m < n.
+// ErrInShape if m < n; TryQR returns the error instead.
 //
 // The QR decomposition always exists, even if the matrix does not have full rank,
 // so QR will never fail unless m < n. The primary use of the QR decomposition is
 // in the least squares solution of non-square systems of simultaneous linear equations.
-// This will fail if QRIsFullRank() returns false. The matrix a is overwritten by the
-// decomposition.
-func QR(a *Dense) QRFactor {
+// This will fail if QRIsFullRank() returns false; QRCP handles that case and m < n.
+// The matrix a is overwritten by the decomposition, unless it is not a *Dense, in
+// which case it is copied first.
+func QR(a Matrix) QRFactor {
+	f, e := TryQR(a)
+	if e != nil {
+		panic(e)
+	}
+	return f
+}
+
+// TryQR is QR returning an error instead of panicking.
+func TryQR(a Matrix) (QRFactor, error) {
 	// Initialize.
 	m, n := a.Dims()
 	if m < n {
-		panic(errInShape)
+		return QRFactor{}, shape_error("QR", ErrInShape, a)
 	}
 
-	qr := a
+	qr := as_dense(a)
 	rDiag := make([]float64, n)
 
 	// Main loop.
@@ -67,7 +79,7 @@ func QR(a *Dense) QRFactor {
 		rDiag[k] = -norm
 	}
 
-	return QRFactor{qr, rDiag}
+	return QRFactor{qr, rDiag}, nil
 }
 
 // IsFullRank returns whether the R matrix and hence a has full rank.
@@ -97,7 +109,7 @@ func (f QRFactor) H() *Dense {
 }
 
 // R returns the upper triangular factor for the QR decomposition.
-func (f QRFactor) R() *Dense {
+func (f QRFactor) R() *TriDense {
 	qr, rDiag := f.QR, f.rDiag
 	_, n := qr.Dims()
 	r := NewDense(n, n)
@@ -110,7 +122,7 @@ func (f QRFactor) R() *Dense {
 			}
 		}
 	}
-	return r
+	return TriDenseView(r, blas.Upper, blas.NonUnit)
 }
 
 // Q generates and returns the (economy-sized) orthogonal factor.
@@ -142,15 +154,19 @@ func (f QRFactor) Q() *Dense {
 // A matrix x is returned that minimizes the two norm of Q*R*X-B. Solve will panic
 // if a is not full rank. The matrix b is overwritten during the call.
 func (f QRFactor) Solve(b *Dense) (x *Dense) {
+	return must_dense(f.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f QRFactor) TrySolve(b *Dense) (x *Dense, e error) {
 	qr := f.QR
-	rDiag := f.rDiag
 	m, n := qr.Dims()
 	bm, bn := b.Dims()
 	if bm != m {
-		panic(errShapes)
+		return nil, shape_error("QRFactor.Solve", ErrShapes, qr, b)
 	}
 	if !f.IsFullRank() {
-		panic("mat64: matrix is rank deficient")
+		return nil, ErrRankDeficient
 	}
 
 	// Compute Y = transpose(Q)*B
@@ -169,19 +185,8 @@ func (f QRFactor) Solve(b *Dense) (x *Dense) {
 	}
 
 	// Solve R*X = Y;
-	for k := n - 1; k >= 0; k-- {
-		row := b.RowView(k)
-		for j := range row[:bn] {
-			row[j] /= rDiag[k]
-		}
-
-		for i := 0; i < k; i++ {
-			row := b.RowView(i)
-			for j := range row[:bn] {
-				row[j] -= b.Get(k, j) * qr.Get(i, k)
-			}
-		}
-	}
+	x = b.SubmatrixView(0, 0, n, bn)
+	SolveTri(f.R(), false, x)
 
-	return b.SubmatrixView(0, 0, n, bn)
+	return x, nil
 }
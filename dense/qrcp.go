@@ -0,0 +1,272 @@
+package dense
+
+import (
+	"math"
+
+	"github.com/gonum/blas"
+)
+
+// QRCPFactors contains the QR decomposition with column pivoting
+//    a * P = Q * R
+// of an m by n matrix a of any shape, where P is a permutation chosen
+// so that the diagonal of R is non-increasing in magnitude, which
+// reveals the numerical rank of a.
+type QRCPFactors struct {
+	qr    *Dense // Householder vectors below the diagonal, R above
+	rDiag []float64
+	perm  []int // col k of a * P is col perm[k] of a
+}
+
+// QRCP computes the QR decomposition of a with column pivoting: at each
+// step, the remaining col of largest norm is moved to the front.
+// Unlike QR, it accepts wide matrices, and does not fail on a rank
+// deficient one. The matrix a is overwritten by the decomposition,
+// unless it is not a *Dense, in which case it is copied first.
+//
+// The norms of the remaining cols are downdated after each step, and
+// recomputed when cancellation makes that inaccurate, as in LAPACK
+// DGEQP3.
+func QRCP(a Matrix) QRCPFactors {
+	m, n := a.Dims()
+	qr := as_dense(a)
+	k := smaller(m, n)
+	rDiag := make([]float64, k)
+	perm := make([]int, n)
+	for j := range perm {
+		perm[j] = j
+	}
+
+	// vn1 holds the norms of the remaining parts of the cols,
+	// vn2 the norms when they were last computed in full.
+	vn1 := make([]float64, n)
+	vn2 := make([]float64, n)
+	for j := 0; j < n; j++ {
+		vn1[j] = col_norm(qr, j, 0)
+		vn2[j] = vn1[j]
+	}
+	tol := math.Sqrt(2.2204e-16)
+
+	for p := 0; p < k; p++ {
+		// Pivot.
+		big := p
+		for j := p + 1; j < n; j++ {
+			if vn1[j] > vn1[big] {
+				big = j
+			}
+		}
+		if big != p {
+			for i := 0; i < m; i++ {
+				row := qr.RowView(i)
+				row[p], row[big] = row[big], row[p]
+			}
+			perm[p], perm[big] = perm[big], perm[p]
+			vn1[p], vn1[big] = vn1[big], vn1[p]
+			vn2[p], vn2[big] = vn2[big], vn2[p]
+		}
+
+		// The Householder reflection, as in QR.
+		norm := col_norm(qr, p, p)
+		if norm != 0 {
+			if qr.Get(p, p) < 0 {
+				norm = -norm
+			}
+			for i := p; i < m; i++ {
+				qr.Set(i, p, qr.Get(i, p)/norm)
+			}
+			qr.Set(p, p, qr.Get(p, p)+1)
+
+			for j := p + 1; j < n; j++ {
+				var s float64
+				for i := p; i < m; i++ {
+					s += qr.Get(i, p) * qr.Get(i, j)
+				}
+				s /= -qr.Get(p, p)
+				for i := p; i < m; i++ {
+					qr.Set(i, j, qr.Get(i, j)+s*qr.Get(i, p))
+				}
+			}
+		}
+		rDiag[p] = -norm
+
+		// Downdate the norms by the elements just moved into R.
+		for j := p + 1; j < n; j++ {
+			if vn1[j] == 0 {
+				continue
+			}
+			t := math.Abs(qr.Get(p, j)) / vn1[j]
+			t = math.Max(0, (1+t)*(1-t))
+			if t*(vn1[j]/vn2[j])*(vn1[j]/vn2[j]) <= tol {
+				vn1[j] = col_norm(qr, j, p+1)
+				vn2[j] = vn1[j]
+			} else {
+				vn1[j] *= math.Sqrt(t)
+			}
+		}
+	}
+
+	return QRCPFactors{qr, rDiag, perm}
+}
+
+// col_norm returns the 2-norm of col j of m from row i on,
+// without under/overflow.
+func col_norm(m *Dense, j, i int) float64 {
+	var norm float64
+	for ; i < m.rows; i++ {
+		norm = math.Hypot(norm, m.Get(i, j))
+	}
+	return norm
+}
+
+// Perm returns the permutation P as a slice p, where col k of a * P
+// is col p[k] of a.
+func (f QRCPFactors) Perm() []int {
+	return append([]int(nil), f.perm...)
+}
+
+// Rank returns the numerical rank of a, that is, the number of diagonal
+// elements of R larger in magnitude than max(m, n) * |R[0][0]| * epsilon.
+// Pass e.g. 2.2204e-16 as epsilon, as for SVDFactors.Rank.
+func (f QRCPFactors) Rank(epsilon float64) int {
+	if len(f.rDiag) == 0 {
+		return 0
+	}
+	m, n := f.qr.Dims()
+	tol := float64(larger(m, n)) * math.Abs(f.rDiag[0]) * epsilon
+	var r int
+	for _, v := range f.rDiag {
+		if math.Abs(v) <= tol {
+			break
+		}
+		r++
+	}
+	return r
+}
+
+// r returns R in a new min(m, n) by n matrix.
+func (f QRCPFactors) r() *Dense {
+	_, n := f.qr.Dims()
+	k := len(f.rDiag)
+	r := NewDense(k, n)
+	for i, v := range f.rDiag {
+		r.Set(i, i, v)
+		copy(r.RowView(i)[i+1:], f.qr.RowView(i)[i+1:])
+	}
+	return r
+}
+
+// R returns the min(m, n) by n upper triangular, or for a wide a,
+// trapezoidal, factor.
+func (f QRCPFactors) R() *TriDense {
+	return TriDenseView(f.r(), blas.Upper, blas.NonUnit)
+}
+
+// Q generates and returns the m by min(m, n) orthogonal factor.
+func (f QRCPFactors) Q() *Dense {
+	qr := f.qr
+	m, _ := qr.Dims()
+	n := len(f.rDiag)
+	q := NewDense(m, n)
+
+	for k := n - 1; k >= 0; k-- {
+		q.Set(k, k, 1)
+		if qr.Get(k, k) == 0 {
+			continue
+		}
+		for j := k; j < n; j++ {
+			var s float64
+			for i := k; i < m; i++ {
+				s += qr.Get(i, k) * q.Get(i, j)
+			}
+			s /= -qr.Get(k, k)
+			for i := k; i < m; i++ {
+				q.Set(i, j, q.Get(i, j)+s*qr.Get(i, k))
+			}
+		}
+	}
+
+	return q
+}
+
+// qt overwrites b by Q' * b.
+func (f QRCPFactors) qt(b *Dense) {
+	qr := f.qr
+	m, bn := b.Dims()
+	for k := range f.rDiag {
+		if qr.Get(k, k) == 0 {
+			continue
+		}
+		for j := 0; j < bn; j++ {
+			var s float64
+			for i := k; i < m; i++ {
+				s += qr.Get(i, k) * b.Get(i, j)
+			}
+			s /= -qr.Get(k, k)
+			for i := k; i < m; i++ {
+				b.Set(i, j, b.Get(i, j)+s*qr.Get(i, k))
+			}
+		}
+	}
+}
+
+// Solve computes the basic least squares solution of a.x = b where b has
+// as many rows as a, treating a as having rank r = f.Rank(epsilon):
+// x minimizes the two norm of a.x - b, and has at most r nonzero rows,
+// those of the first r cols of a * P. The matrix b is overwritten during
+// the call; x is newly allocated, with as many rows as a has cols.
+func (f QRCPFactors) Solve(b *Dense, epsilon float64) *Dense {
+	return must_dense(f.TrySolve(b, epsilon))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f QRCPFactors) TrySolve(b *Dense, epsilon float64) (*Dense, error) {
+	return f.solve("QRCPFactors.Solve", b, epsilon, false)
+}
+
+// SolveMinNorm is Solve, but returns the least squares solution of
+// smallest norm, which is unique. It takes a further QR decomposition of
+// the first r rows of R, which is the complete orthogonal decomposition
+//    a * P = Q * [T 0] * Z'
+// with T r by r lower triangular.
+func (f QRCPFactors) SolveMinNorm(b *Dense, epsilon float64) *Dense {
+	return must_dense(f.TrySolveMinNorm(b, epsilon))
+}
+
+// TrySolveMinNorm is SolveMinNorm returning an error instead of panicking.
+func (f QRCPFactors) TrySolveMinNorm(b *Dense, epsilon float64) (*Dense, error) {
+	return f.solve("QRCPFactors.SolveMinNorm", b, epsilon, true)
+}
+
+func (f QRCPFactors) solve(op string, b *Dense, epsilon float64, minNorm bool) (*Dense, error) {
+	m, n := f.qr.Dims()
+	bm, bn := b.Dims()
+	if bm != m {
+		return nil, shape_error(op, ErrShapes, f.qr, b)
+	}
+	x := NewDense(n, bn)
+	r := f.Rank(epsilon)
+	if r == 0 {
+		return x, nil
+	}
+
+	// Compute Y = transpose(Q)*B; its first r rows are what R can match.
+	f.qt(b)
+	y := b.SubmatrixView(0, 0, r, bn)
+	r1 := f.r().SubmatrixView(0, 0, r, n)
+
+	var z *Dense
+	if minNorm {
+		// R1 = T * Z' with R1' = Z * T' by QR; then z = Z * (T \ Y).
+		zt := QR(r1.TView())
+		SolveTri(zt.R(), true, y)
+		z = Mult(zt.Q(), y, nil)
+	} else {
+		SolveTri(TriDenseView(r1.SubmatrixView(0, 0, r, r), blas.Upper, blas.NonUnit), false, y)
+		z = y
+	}
+
+	// Undo the permutation: row k of z is row perm[k] of x.
+	for k := 0; k < z.rows; k++ {
+		x.SetRow(f.perm[k], z.RowView(k))
+	}
+	return x, nil
+}
@@ -0,0 +1,121 @@
+package dense
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	check "launchpad.net/gocheck"
+)
+
+// rand_rank returns an m by n matrix of rank r.
+func rand_rank(m, n, r int) *Dense {
+	if r == 0 {
+		return NewDense(m, n)
+	}
+	u, v := NewDense(m, r), NewDense(r, n)
+	for _, x := range [][]float64{u.data, v.data} {
+		for i := range x {
+			x[i] = rand.NormFloat64()
+		}
+	}
+	return Mult(u, v, nil)
+}
+
+// pinv_solve returns pinv(a) * b by the SVD of the tall a.
+func pinv_solve(a, b *Dense) *Dense {
+	f := SVD(Clone(a), 2.2204e-16, 1e-300, true, true)
+	ub := Mult(f.U.TView(), b, nil)
+	for i, s := range f.Sigma {
+		if s > 1e-10*f.Sigma[0] {
+			scale_row(ub.RowView(i), 1/s)
+		} else {
+			ub.SetRow(i, make([]float64, ub.cols))
+		}
+	}
+	return Mult(f.V, ub, nil)
+}
+
+func scale_row(x []float64, v float64) {
+	for i := range x {
+		x[i] *= v
+	}
+}
+
+func (s *S) TestQRCP(c *check.C) {
+	for _, t := range []struct {
+		m, n, rank int
+	}{
+		{6, 4, 4},
+		{6, 4, 2},
+		{4, 6, 4},
+		{4, 6, 3},
+		{5, 5, 0},
+		{1, 3, 1},
+	} {
+		a := rand_rank(t.m, t.n, t.rank)
+		f := QRCP(Clone(a))
+		q, r := f.Q(), f.R()
+		k := smaller(t.m, t.n)
+		rr, _ := r.Dims()
+		c.Check(rr, check.Equals, k)
+		c.Check(isOrthogonal(q), check.Equals, true)
+		c.Check(isUpperTriangular(r), check.Equals, true)
+
+		ap := NewDense(t.m, t.n)
+		for j, p := range f.Perm() {
+			ap.SetCol(j, a.GetCol(p, nil))
+		}
+		c.Check(Approx(Mult(q, r, nil), ap, 1e-10), check.Equals, true,
+			check.Commentf("%dx%d", t.m, t.n))
+		for i := 1; i < k; i++ {
+			c.Check(math.Abs(r.At(i, i)) <= math.Abs(r.At(i-1, i-1))*(1+1e-12), check.Equals, true)
+		}
+		c.Check(f.Rank(2.2204e-16), check.Equals, t.rank, check.Commentf("%dx%d", t.m, t.n))
+	}
+}
+
+func (s *S) TestQRCPSolve(c *check.C) {
+	// Full rank and tall: the same as QR.
+	a := rand_rank(6, 4, 4)
+	b := rand_rank(6, 2, 2)
+	want := QR(Clone(a)).Solve(Clone(b))
+	c.Check(Approx(QRCP(Clone(a)).Solve(Clone(b), 2.2204e-16), want, 1e-8), check.Equals, true)
+	c.Check(Approx(QRCP(Clone(a)).SolveMinNorm(Clone(b), 2.2204e-16), want, 1e-8), check.Equals, true)
+
+	// Rank deficient: both solutions are least squares solutions, with
+	// the same residual, and the min-norm one is pinv(a) * b.
+	a = rand_rank(6, 4, 2)
+	f := QRCP(Clone(a))
+	basic := f.Solve(Clone(b), 2.2204e-16)
+	minNorm := f.SolveMinNorm(Clone(b), 2.2204e-16)
+	c.Check(Approx(minNorm, pinv_solve(a, b), 1e-8), check.Equals, true)
+	res := Subtract(Mult(a, basic, nil), b, nil)
+	c.Check(Approx(Mult(a.TView(), res, nil), NewDense(4, 2), 1e-8), check.Equals, true)
+	c.Check(Approx(Mult(a, minNorm, nil), Mult(a, basic, nil), 1e-8), check.Equals, true)
+	for j := 0; j < 2; j++ {
+		zeros := 0
+		for i := 0; i < 4; i++ {
+			if basic.Get(i, j) == 0 {
+				zeros++
+			}
+		}
+		c.Check(zeros, check.Equals, 2)
+		c.Check(minNorm.SubmatrixView(0, j, 4, 1).Norm(2) < basic.SubmatrixView(0, j, 4, 1).Norm(2),
+			check.Equals, true)
+	}
+
+	// Wide and full rank: a * x = b exactly, and the min-norm solution
+	// is a' * (a * a')^-1 * b.
+	a = rand_rank(3, 5, 3)
+	b = rand_rank(3, 1, 1)
+	x := QRCP(Clone(a)).SolveMinNorm(Clone(b), 2.2204e-16)
+	c.Check(Approx(Mult(a, x, nil), b, 1e-8), check.Equals, true)
+	w := LU(Mult(a, a.TView(), nil)).Solve(Clone(b))
+	c.Check(Approx(x, Mult(a.TView(), w, nil), 1e-8), check.Equals, true)
+	x = QRCP(Clone(a)).Solve(Clone(b), 2.2204e-16)
+	c.Check(Approx(Mult(a, x, nil), b, 1e-8), check.Equals, true)
+
+	_, e := QRCP(a).TrySolve(NewDense(4, 1), 2.2204e-16)
+	c.Check(errors.Is(e, ErrShapes), check.Equals, true)
+}
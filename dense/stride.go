@@ -7,7 +7,7 @@ type Float64Stride struct {
 
 func NewFloat64Stride(data []float64, stride int) *Float64Stride {
 	if data == nil || len(data) < 1 {
-		panic(errInLength)
+		panic(ErrInLength)
 	}
 	if stride < 1 {
 		panic(err("stride must be positive"))
@@ -49,7 +49,7 @@ func (me *Float64Stride) Swap(i, j int) *Float64Stride {
 func (me *Float64Stride) CopyFromSlice(in []float64) *Float64Stride {
 	n := me.Len()
 	if len(in) != n {
-		panic(errInLength)
+		panic(ErrInLength)
 	}
 	for i, j := 0, 0; i < n; i, j = i+1, j+me.stride {
 		me.data[j] = in[i]
@@ -59,7 +59,7 @@ func (me *Float64Stride) CopyFromSlice(in []float64) *Float64Stride {
 
 func (me *Float64Stride) CopyToSlice(out []float64) []float64 {
 	n := me.Len()
-	out = use_slice(out, n, errOutLength)
+	out = use_slice(out, n, ErrOutLength)
 	for i, j := 0, 0; i < n; i, j = i+1, j+me.stride {
 		out[i] = me.data[j]
 	}
@@ -136,7 +136,7 @@ func (me *Float64Stride) Prod() float64 {
 func copy_stride(dest, src *Float64Stride) {
 	n := dest.Len()
 	if src.Len() != n {
-		panic(errLengths)
+		panic(ErrLengths)
 	}
 	for i := 0; i < n; i++ {
 		dest.Set(i, src.Get(i))
@@ -20,7 +20,8 @@ type SVDFactors struct {
 // The singular value decomposition is an m-by-n orthogonal matrix u, an n-by-n
 // diagonal matrix s, and an n-by-n orthogonal matrix v so that a = u*s*v'.
 // If a is a wide matrix a copy of its transpose is allocated, otherwise
-// a is overwritten during the decomposition.
+// a is overwritten during the decomposition, unless it is not a *Dense,
+// in which case it is copied first.
 // Matrices u and v are only returned when wantu and wantv are true respectively.
 //
 // The singular values, sigma[k] = s[k][k], are ordered so that
@@ -29,15 +30,18 @@ type SVDFactors struct {
 //
 // The matrix condition number and the effective numerical rank can be computed from
 // this decomposition.
-func SVD(a *Dense, epsilon, small float64, wantu, wantv bool) SVDFactors {
-	m, n := a.Dims()
+func SVD(in Matrix, epsilon, small float64, wantu, wantv bool) SVDFactors {
+	m, n := in.Dims()
 
+	var a *Dense
 	trans := false
 	if m < n {
-		a = T(a, nil)
+		a = Clone(Transpose{in})
 		m, n = n, m
 		wantu, wantv = wantv, wantu
 		trans = true
+	} else {
+		a = as_dense(in)
 	}
 
 	sigma := make([]float64, smaller(m+1, n))
@@ -0,0 +1,178 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+)
+
+// SymDense is a symmetric n by n matrix that stores only one of its
+// triangles: the upper (including the diagonal) if uplo is blas.Upper,
+// the lower if uplo is blas.Lower.
+//
+// In full storage, the triangle lives in an n by n row-major array,
+// the other half of which is not referenced.
+// In packed storage, the n*(n+1)/2 elements of the triangle are stored
+// row by row without gaps, as in the BLAS packed formats.
+//
+// Mult recognizes a SymDense operand and computes the product by Dsymm.
+type SymDense struct {
+	n      int
+	uplo   blas.Uplo
+	packed bool
+	data   []float64
+}
+
+// NewSymDense creates an all-zero n by n symmetric matrix
+// storing the triangle ul, in packed storage if packed is true.
+func NewSymDense(n int, ul blas.Uplo, packed bool) *SymDense {
+	if ul != blas.Upper && ul != blas.Lower {
+		panic(err("illegal uplo flag"))
+	}
+	size := n * n
+	if packed {
+		size = n * (n + 1) / 2
+	}
+	return &SymDense{n, ul, packed, make([]float64, size)}
+}
+
+// SymDenseFrom creates a symmetric matrix from the triangle ul of the
+// square matrix m. The other triangle of m is not referenced.
+func SymDenseFrom(m Matrix, ul blas.Uplo, packed bool) *SymDense {
+	r, c := m.Dims()
+	if r != c {
+		panic(shape_error("SymDenseFrom", ErrSquare, m))
+	}
+	s := NewSymDense(r, ul, packed)
+	for i := 0; i < r; i++ {
+		from, to := 0, i+1
+		if ul == blas.Upper {
+			from, to = i, r
+		}
+		for j := from; j < to; j++ {
+			s.data[s.idx(i, j)] = m.At(i, j)
+		}
+	}
+	return s
+}
+
+func (s *SymDense) Dims() (r, c int) { return s.n, s.n }
+
+// Uplo returns the triangle that is stored.
+func (s *SymDense) Uplo() blas.Uplo { return s.uplo }
+
+// Packed reports whether s is in packed storage.
+func (s *SymDense) Packed() bool { return s.packed }
+
+// idx returns the index of element (r, c) in the internal data slice,
+// where (r, c) must be in the stored triangle.
+func (s *SymDense) idx(r, c int) int {
+	if !s.packed {
+		return r*s.n + c
+	}
+	if s.uplo == blas.Upper {
+		return r*(2*s.n-r+1)/2 + c - r
+	}
+	return r*(r+1)/2 + c
+}
+
+// stored maps (r, c) into the stored triangle.
+func (s *SymDense) stored(r, c int) (int, int) {
+	if r < 0 || r >= s.n || c < 0 || c >= s.n {
+		panic(ErrIndexOutOfRange)
+	}
+	if (s.uplo == blas.Upper) == (r > c) {
+		return c, r
+	}
+	return r, c
+}
+
+func (s *SymDense) At(r, c int) float64 {
+	return s.data[s.idx(s.stored(r, c))]
+}
+
+func (s *SymDense) Get(r, c int) float64 {
+	return s.At(r, c)
+}
+
+// Set sets elements (r, c) and (c, r) to v.
+func (s *SymDense) Set(r, c int, v float64) *SymDense {
+	s.data[s.idx(s.stored(r, c))] = v
+	return s
+}
+
+// Full copies s into out, filling in both triangles, and returns out.
+// If out is nil, a new matrix is allocated and used.
+func (s *SymDense) Full(out *Dense) *Dense {
+	out = use_dense(out, s.n, s.n, ErrOutShape)
+	Copy(out, s)
+	return out
+}
+
+// full_storage returns the triangle of s in full storage, with its
+// stride, as the BLAS routines want it. For packed s, this is a copy;
+// pass it to store_full to write back changes.
+func (s *SymDense) full_storage() ([]float64, int) {
+	stride := larger(s.n, 1)
+	if !s.packed {
+		return s.data, stride
+	}
+	full := make([]float64, s.n*s.n)
+	for i := 0; i < s.n; i++ {
+		from, to := 0, i+1
+		if s.uplo == blas.Upper {
+			from, to = i, s.n
+		}
+		copy(full[i*s.n+from:i*s.n+to], s.data[s.idx(i, from):])
+	}
+	return full, stride
+}
+
+// store_full writes the triangle in full, obtained from full_storage,
+// back into s.
+func (s *SymDense) store_full(full []float64) {
+	if !s.packed {
+		return
+	}
+	for i := 0; i < s.n; i++ {
+		from, to := 0, i+1
+		if s.uplo == blas.Upper {
+			from, to = i, s.n
+		}
+		copy(s.data[s.idx(i, from):], full[i*s.n+from:i*s.n+to])
+	}
+}
+
+// symm computes alpha * s * b + beta * c if side is blas.Left,
+// or alpha * b * s + beta * c if side is blas.Right, by Dsymm.
+// c has the correct shape.
+func symm(side blas.Side, alpha float64, s *SymDense, b Matrix, beta float64, c *Dense) *Dense {
+	bd, t, balpha := blas_operand(b)
+	if t == blas.Trans {
+		// Dsymm does not take a transposed b.
+		bd, balpha = Clone(b), 1
+	}
+	out := scratch_out(c, beta != 0, bd)
+	data, stride := s.full_storage()
+	blasEngine.Dsymm(blasOrder, side, s.uplo, c.rows, c.cols,
+		alpha*balpha, data, stride, bd.data, bd.stride,
+		beta, out.data, out.stride)
+	flush_out(c, out)
+	return c
+}
+
+// SymRankK performs the symmetric rank-k update
+//    s = s + alpha * a * a'
+// by Dsyrk, where a is n by k.
+// s is updated in-place, and is also returned.
+func (s *SymDense) SymRankK(alpha float64, a Matrix) *SymDense {
+	r, k := a.Dims()
+	if r != s.n {
+		panic(shape_error("SymRankK", ErrShapes, s, a))
+	}
+	ad, t, aalpha := blas_operand(a)
+	data, stride := s.full_storage()
+	blasEngine.Dsyrk(blasOrder, s.uplo, t, s.n, k,
+		alpha*aalpha*aalpha, ad.data, ad.stride,
+		1, data, stride)
+	s.store_full(data)
+	return s
+}
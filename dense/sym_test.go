@@ -0,0 +1,78 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestSymDense(c *check.C) {
+	full := flatten2dense([][]float64{
+		{4, 1, 2},
+		{1, 5, 3},
+		{2, 3, 6},
+	})
+	for _, ul := range []blas.Uplo{blas.Upper, blas.Lower} {
+		for _, packed := range []bool{false, true} {
+			sym := SymDenseFrom(full, ul, packed)
+			comment := check.Commentf("uplo %v, packed %v", ul, packed)
+			if packed {
+				c.Check(len(sym.data), check.Equals, 6, comment)
+			}
+			c.Check(Equal(sym, full), check.Equals, true, comment)
+			c.Check(Equal(sym.Full(nil), full), check.Equals, true, comment)
+
+			sym.Set(2, 0, -7)
+			c.Check(sym.At(0, 2), check.Equals, -7.0, comment)
+			sym.Set(0, 2, 2)
+
+			b := rand_dense(3, 4)
+			c.Check(Approx(Mult(sym, b, nil), Mult(full, b, nil), 1e-12),
+				check.Equals, true, comment)
+			c.Check(Approx(Mult(b.TView(), sym, nil), Mult(b.TView(), full, nil), 1e-12),
+				check.Equals, true, comment)
+			out := rand_dense(4, 3)
+			want := Clone(out)
+			naive_gemm(true, false, 2, b, full, 0.5, want)
+			Gemm(true, false, 2, b, Clone(full), 0.5, out)
+			c.Check(Approx(out, want, 1e-12), check.Equals, true, comment)
+
+			x := rand_dense(3, 2)
+			sym.SymRankK(-0.5, x)
+			c.Check(Approx(sym, AddScaled(full, MultTrans(x, x, nil), -0.5, nil), 1e-12),
+				check.Equals, true, comment)
+			sym.SymRankK(0.5, T(x, nil).TView())
+			c.Check(Approx(sym, full, 1e-12), check.Equals, true, comment)
+
+			ch, ok := Chol(sym)
+			c.Check(ok, check.Equals, true, comment)
+			c.Check(Approx(MultTrans(ch.L(), ch.L(), nil), full, 1e-12),
+				check.Equals, true, comment)
+
+			before := sym.Full(nil)
+			ef := Eigen(sym, 2.2204e-16)
+			c.Check(Approx(Mult(full, ef.V, nil), Mult(ef.V, ef.D(), nil), 1e-12),
+				check.Equals, true, comment)
+			c.Check(Equal(sym, before), check.Equals, true, comment)
+		}
+	}
+}
+
+func (s *S) TestNativeSymm(c *check.C) {
+	a := SymDenseFrom(rand_dense(4, 4), blas.Lower, false)
+	af := a.Full(nil)
+	b := rand_dense(4, 3)
+	want := Mult(af, b, nil)
+
+	// Column major: pass the row-major transposes.
+	got := NewDense(3, 4)
+	native{}.Dsymm(blas.ColMajor, blas.Left, blas.Upper, 4, 3,
+		1, a.data, 4, T(b, nil).data, 4, 0, got.data, 4)
+	c.Check(Approx(T(got, nil), want, 1e-12), check.Equals, true)
+
+	x := rand_dense(4, 2)
+	cc := NewDense(4, 4)
+	native{}.Dsyrk(blas.ColMajor, blas.Upper, blas.NoTrans, 4, 2,
+		1, T(x, nil).data, 4, 0, cc.data, 4)
+	c.Check(Approx(SymDenseFrom(cc, blas.Lower, false), MultTrans(x, x, nil), 1e-12),
+		check.Equals, true)
+}
@@ -0,0 +1,249 @@
+package dense
+
+import (
+	"github.com/gonum/blas"
+)
+
+// TriDense is a triangular matrix: upper (elements below the diagonal
+// are zero) if uplo is blas.Upper, lower if uplo is blas.Lower.
+// If diag is blas.Unit, the diagonal elements are 1.
+//
+// The triangle lives in a Dense, whose other elements, and whose
+// diagonal if diag is blas.Unit, are not referenced. The factorization
+// types return their triangular factors as TriDense views of their
+// internal storage in this way.
+//
+// A TriDense is usually square; a non-square one is trapezoidal.
+// SolveTri, MultTri and InvTri require a square matrix.
+type TriDense struct {
+	mat  *Dense
+	uplo blas.Uplo
+	diag blas.Diag
+}
+
+// NewTriDense creates an all-zero n by n triangular matrix.
+func NewTriDense(n int, ul blas.Uplo, d blas.Diag) *TriDense {
+	return TriDenseView(NewDense(n, n), ul, d)
+}
+
+// TriDenseView returns the triangle ul of m as a triangular matrix
+// that shares storage with m.
+func TriDenseView(m *Dense, ul blas.Uplo, d blas.Diag) *TriDense {
+	if ul != blas.Upper && ul != blas.Lower {
+		panic(err("illegal uplo flag"))
+	}
+	if d != blas.NonUnit && d != blas.Unit {
+		panic(err("illegal diag flag"))
+	}
+	return &TriDense{m, ul, d}
+}
+
+func (t *TriDense) Dims() (r, c int) { return t.mat.rows, t.mat.cols }
+
+// Uplo returns the triangle that is stored.
+func (t *TriDense) Uplo() blas.Uplo { return t.uplo }
+
+// Diag returns whether the diagonal is unit.
+func (t *TriDense) Diag() blas.Diag { return t.diag }
+
+// in_triangle reports whether (r, c) is in the triangle,
+// diagonal included.
+func (t *TriDense) in_triangle(r, c int) bool {
+	if t.uplo == blas.Upper {
+		return r <= c
+	}
+	return r >= c
+}
+
+func (t *TriDense) At(r, c int) float64 {
+	if r < 0 || r >= t.mat.rows || c < 0 || c >= t.mat.cols {
+		panic(ErrIndexOutOfRange)
+	}
+	switch {
+	case r == c && t.diag == blas.Unit:
+		return 1
+	case !t.in_triangle(r, c):
+		return 0
+	}
+	return t.mat.data[t.mat.idx(r, c)]
+}
+
+func (t *TriDense) Get(r, c int) float64 {
+	return t.At(r, c)
+}
+
+// Set sets element (r, c), which must be in the triangle,
+// and not on the diagonal if that is unit, to v.
+func (t *TriDense) Set(r, c int, v float64) *TriDense {
+	if !t.in_triangle(r, c) || (r == c && t.diag == blas.Unit) {
+		panic(err("element not in the triangle"))
+	}
+	t.mat.Set(r, c, v)
+	return t
+}
+
+// TView returns the transpose of t as a view.
+func (t *TriDense) TView() Transpose {
+	return Transpose{t}
+}
+
+// Full copies t into out, with zeros outside the triangle, and
+// returns out. If out is nil, a new matrix is allocated and used.
+func (t *TriDense) Full(out *Dense) *Dense {
+	out = use_dense(out, t.mat.rows, t.mat.cols, ErrOutShape)
+	Copy(out, t)
+	return out
+}
+
+// square returns the storage of the square t, copied if it shares
+// storage with b, which is about to be written.
+func (t *TriDense) square(op string, b *Dense) (*Dense, error) {
+	if t.mat.rows != t.mat.cols {
+		return nil, shape_error(op, ErrSquare, t)
+	}
+	if overlap(b, t.mat) {
+		return Clone(t.mat), nil
+	}
+	return t.mat, nil
+}
+
+// singular reports whether a diagonal element of t is zero.
+func (t *TriDense) singular() bool {
+	if t.diag == blas.Unit {
+		return false
+	}
+	for i := 0; i < t.mat.rows; i++ {
+		if t.mat.data[t.mat.idx(i, i)] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func trans_flag(trans bool) blas.Transpose {
+	if trans {
+		return blas.Trans
+	}
+	return blas.NoTrans
+}
+
+// trsm solves op(t) * x = b if side is blas.Left,
+// or x * op(t) = b if side is blas.Right, by Dtrsm.
+func trsm(op string, side blas.Side, t *TriDense, trans bool, b *Dense) (*Dense, error) {
+	a, e := t.square(op, b)
+	if e != nil {
+		return nil, e
+	}
+	if (side == blas.Left && b.rows != a.rows) ||
+		(side == blas.Right && b.cols != a.cols) {
+		return nil, shape_error(op, ErrShapes, t, b)
+	}
+	if t.singular() {
+		return nil, ErrSingular
+	}
+	blasEngine.Dtrsm(blasOrder, side, t.uplo, trans_flag(trans), t.diag,
+		b.rows, b.cols, 1, a.data, larger(a.stride, 1), b.data, larger(b.stride, 1))
+	return b, nil
+}
+
+// SolveTri solves
+//    t * x = b     if trans is false, or
+//    t' * x = b    if trans is true,
+// for x by Dtrsm. b is overwritten by x, and is returned.
+// SolveTri panics with ErrSingular if t has a zero on the diagonal.
+func SolveTri(t *TriDense, trans bool, b *Dense) *Dense {
+	return must_dense(TrySolveTri(t, trans, b))
+}
+
+// TrySolveTri is SolveTri returning an error instead of panicking.
+func TrySolveTri(t *TriDense, trans bool, b *Dense) (*Dense, error) {
+	return trsm("SolveTri", blas.Left, t, trans, b)
+}
+
+// SolveTriR is SolveTri with t on the right, that is, it solves
+// x * t = b, or x * t' = b if trans is true.
+func SolveTriR(t *TriDense, trans bool, b *Dense) *Dense {
+	return must_dense(TrySolveTriR(t, trans, b))
+}
+
+// TrySolveTriR is SolveTriR returning an error instead of panicking.
+func TrySolveTriR(t *TriDense, trans bool, b *Dense) (*Dense, error) {
+	return trsm("SolveTriR", blas.Right, t, trans, b)
+}
+
+// MultTri computes t * b, or t' * b if trans is true, by Dtrmm.
+// b is overwritten by the product, and is returned.
+func MultTri(t *TriDense, trans bool, b *Dense) *Dense {
+	return must_dense(TryMultTri(t, trans, b))
+}
+
+// TryMultTri is MultTri returning an error instead of panicking.
+func TryMultTri(t *TriDense, trans bool, b *Dense) (*Dense, error) {
+	a, e := t.square("MultTri", b)
+	if e != nil {
+		return nil, e
+	}
+	if b.rows != a.rows {
+		return nil, shape_error("MultTri", ErrShapes, t, b)
+	}
+	blasEngine.Dtrmm(blasOrder, blas.Left, t.uplo, trans_flag(trans), t.diag,
+		b.rows, b.cols, 1, a.data, larger(a.stride, 1), b.data, larger(b.stride, 1))
+	return b, nil
+}
+
+// InvTri returns the inverse of t, which is triangular of the same kind.
+// If out is nil, a new matrix is allocated and used;
+// otherwise out must have the same shape and kind (uplo and diag) as t,
+// and may be t itself. Only the triangle of out is written; its other
+// elements, and its diagonal if diag is blas.Unit, are left unchanged,
+// so that out may be a view of the storage of a factorization.
+// InvTri panics with ErrSingular if t has a zero on the diagonal.
+func InvTri(t, out *TriDense) *TriDense {
+	out, e := TryInvTri(t, out)
+	if e != nil {
+		panic(e)
+	}
+	return out
+}
+
+// TryInvTri is InvTri returning an error instead of panicking.
+func TryInvTri(t, out *TriDense) (*TriDense, error) {
+	n, c := t.Dims()
+	if n != c {
+		return nil, shape_error("InvTri", ErrSquare, t)
+	}
+	var mat *Dense
+	if out != nil {
+		if out.uplo != t.uplo || out.diag != t.diag {
+			return nil, shape_error("InvTri", ErrOutShape, t, out)
+		}
+		mat = out.mat
+	}
+	mat, e := try_use_dense("InvTri", mat, n, n)
+	if e != nil {
+		return nil, e
+	}
+	// Solve t * x = I in scratch space, since out may be t.
+	x := NewDense(n, n).FillDiag(1)
+	if _, e := TrySolveTri(t, false, x); e != nil {
+		return nil, e
+	}
+	for i := 0; i < n; i++ {
+		lo, hi := 0, i+1
+		if t.uplo == blas.Upper {
+			lo, hi = i, n
+		}
+		if t.diag == blas.Unit {
+			if t.uplo == blas.Upper {
+				lo++
+			} else {
+				hi--
+			}
+		}
+		copy(mat.RowView(i)[lo:hi], x.RowView(i)[lo:hi])
+	}
+	if out == nil {
+		out = &TriDense{mat, t.uplo, t.diag}
+	}
+	return out, nil
+}
@@ -0,0 +1,187 @@
+package dense
+
+import (
+	"errors"
+
+	"github.com/gonum/blas"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestTriDense(c *check.C) {
+	m := make_dense(3, 3, []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	})
+
+	u := TriDenseView(m, blas.Upper, blas.NonUnit)
+	c.Check(Equal(u, make_dense(3, 3, []float64{
+		1, 2, 3,
+		0, 5, 6,
+		0, 0, 9,
+	})), check.Equals, true)
+
+	l := TriDenseView(m, blas.Lower, blas.Unit)
+	c.Check(Equal(l.Full(nil), make_dense(3, 3, []float64{
+		1, 0, 0,
+		4, 1, 0,
+		7, 8, 1,
+	})), check.Equals, true)
+
+	l.Set(2, 0, -1)
+	c.Check(m.Get(2, 0), check.Equals, -1.0)
+	c.Check(func() { l.Set(0, 2, 1) }, check.PanicMatches, "dense: element not in the triangle")
+	c.Check(func() { l.Set(1, 1, 2) }, check.PanicMatches, "dense: element not in the triangle")
+	c.Check(func() { u.At(3, 0) }, check.PanicMatches, ErrIndexOutOfRange.Error())
+
+	// Trapezoidal.
+	t := TriDenseView(make_dense(2, 3, []float64{1, 2, 3, 4, 5, 6}), blas.Lower, blas.NonUnit)
+	c.Check(Equal(t, make_dense(2, 3, []float64{1, 0, 0, 4, 5, 0})), check.Equals, true)
+	_, e := TrySolveTri(t, false, NewDense(2, 1))
+	c.Check(e, check.ErrorMatches, "dense: expect square matrix in SolveTri: 2x3")
+}
+
+func (s *S) TestTriSolveMult(c *check.C) {
+	for _, ul := range []blas.Uplo{blas.Upper, blas.Lower} {
+		for _, d := range []blas.Diag{blas.NonUnit, blas.Unit} {
+			a := rand_dense(5, 5)
+			for i := 0; i < 5; i++ {
+				a.Set(i, i, a.Get(i, i)+4)
+			}
+			t := TriDenseView(a, ul, d)
+			full := t.Full(nil)
+			for _, trans := range []bool{false, true} {
+				comment := check.Commentf("uplo %v, diag %v, trans %v", ul, d, trans)
+				op := full
+				if trans {
+					op = T(full, nil)
+				}
+
+				b := rand_dense(5, 3)
+				c.Check(Approx(MultTri(t, trans, Clone(b)), Mult(op, b, nil), 1e-12),
+					check.Equals, true, comment)
+				x := SolveTri(t, trans, Clone(b))
+				c.Check(Approx(Mult(op, x, nil), b, 1e-12), check.Equals, true, comment)
+
+				b = rand_dense(3, 5)
+				x = SolveTriR(t, trans, Clone(b))
+				c.Check(Approx(Mult(x, op, nil), b, 1e-12), check.Equals, true, comment)
+			}
+
+			inv := InvTri(t, nil)
+			c.Check(inv.Uplo(), check.Equals, ul)
+			c.Check(Approx(Mult(inv, t, nil), eye(5), 1e-12), check.Equals, true)
+			// In-place: only the triangle of the backing Dense changes.
+			want := Clone(a)
+			for i := 0; i < 5; i++ {
+				for j := 0; j < 5; j++ {
+					if t.in_triangle(i, j) && (i != j || d == blas.NonUnit) {
+						want.Set(i, j, inv.At(i, j))
+					}
+				}
+			}
+			InvTri(t, t)
+			c.Check(Equal(t, inv), check.Equals, true)
+			c.Check(Equal(a, want), check.Equals, true, check.Commentf("uplo %v, diag %v", ul, d))
+		}
+	}
+
+	// A factor inverted in place leaves the other factor sharing its
+	// storage, as in a packed LU, alone.
+	lu := rand_dense(4, 4)
+	lu.FillDiag(3)
+	l := TriDenseView(lu, blas.Lower, blas.Unit)
+	u := TriDenseView(lu, blas.Upper, blas.NonUnit)
+	uFull := u.Full(nil)
+	want := InvTri(l, nil).Full(nil)
+	InvTri(l, l)
+	c.Check(Approx(l.Full(nil), want, 1e-12), check.Equals, true)
+	c.Check(Equal(u, uFull), check.Equals, true)
+
+	_, e := TryInvTri(l, u)
+	c.Check(errors.Is(e, ErrOutShape), check.Equals, true)
+
+	// b overlapping t.
+	a := rand_dense(4, 4)
+	a.FillDiag(3)
+	t := TriDenseView(a, blas.Upper, blas.NonUnit)
+	want = MultTri(t, false, Clone(a))
+	c.Check(Approx(MultTri(t, false, a), want, 1e-12), check.Equals, true)
+
+	t = TriDenseView(eye(3).Set(1, 1, 0), blas.Lower, blas.NonUnit)
+	_, e = TrySolveTri(t, false, eye(3))
+	c.Check(e, check.Equals, ErrSingular)
+	c.Check(func() { InvTri(t, nil) }, check.Panics, ErrSingular)
+}
+
+func (s *S) TestFactorsTri(c *check.C) {
+	a := make_dense(3, 3, []float64{
+		4, 2, 1,
+		2, 5, 3,
+		1, 3, 6,
+	})
+
+	lf := LU(Clone(a))
+	c.Check(lf.L().Diag(), check.Equals, blas.Unit)
+	c.Check(lf.U().Uplo(), check.Equals, blas.Upper)
+	c.Check(Approx(Mult(lf.L(), lf.U(), nil), pivotRows(Clone(a), lf.pivot), 1e-12),
+		check.Equals, true)
+
+	qf := QR(Clone(a))
+	c.Check(Approx(Mult(qf.Q(), qf.R(), nil), a, 1e-12), check.Equals, true)
+
+	ch, ok := Chol(a)
+	c.Check(ok, check.Equals, true)
+	l := ch.L()
+	c.Check(l.Uplo(), check.Equals, blas.Lower)
+	c.Check(Approx(Mult(l, l.TView(), nil), a, 1e-12), check.Equals, true)
+}
+
+func (s *S) TestNativeTrsmTrmm(c *check.C) {
+	const m, n = 4, 6
+	for _, side := range []blas.Side{blas.Left, blas.Right} {
+		k := m
+		if side == blas.Right {
+			k = n
+		}
+		for _, ul := range []blas.Uplo{blas.Upper, blas.Lower} {
+			for _, tA := range []blas.Transpose{blas.NoTrans, blas.Trans} {
+				for _, d := range []blas.Diag{blas.NonUnit, blas.Unit} {
+					comment := check.Commentf("side %v, uplo %v, trans %v, diag %v", side, ul, tA, d)
+					a := rand_dense(k, k)
+					for i := 0; i < k; i++ {
+						a.Set(i, i, a.Get(i, i)+4)
+					}
+					op := TriDenseView(a, ul, d).Full(nil)
+					if tA == blas.Trans {
+						op = T(op, nil)
+					}
+					b := rand_dense(m, n)
+					want := NewDense(m, n)
+					if side == blas.Left {
+						naive_gemm(false, false, 2, op, b, 0, want)
+					} else {
+						naive_gemm(false, false, 2, b, op, 0, want)
+					}
+
+					got := Clone(b)
+					native{}.Dtrmm(blas.RowMajor, side, ul, tA, d, m, n,
+						2, a.data, a.stride, got.data, got.stride)
+					c.Check(Approx(got, want, 1e-12), check.Equals, true, comment)
+					native{}.Dtrsm(blas.RowMajor, side, ul, tA, d, m, n,
+						0.5, a.data, a.stride, got.data, got.stride)
+					c.Check(Approx(got, b, 1e-12), check.Equals, true, comment)
+
+					// Column major: the transposed data.
+					got = T(b, nil)
+					native{}.Dtrmm(blas.ColMajor, side, ul, tA, d, m, n,
+						2, T(a, nil).data, k, got.data, m)
+					c.Check(Approx(T(got, nil), want, 1e-12), check.Equals, true, comment)
+					native{}.Dtrsm(blas.ColMajor, side, ul, tA, d, m, n,
+						0.5, T(a, nil).data, k, got.data, m)
+					c.Check(Approx(T(got, nil), b, 1e-12), check.Equals, true, comment)
+				}
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dense
 
 import (
 	"math"
+	"strconv"
 )
 
 // add returns slice out whose elements are
@@ -14,7 +15,7 @@ func add(x, y, out []float64) []float64 {
 	if len(x) != len(y) {
 		panic("input length mismatch")
 	}
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, v := range x {
 		out[i] = v + y[i]
 	}
@@ -31,7 +32,7 @@ func add_scaled(x, y []float64, s float64, out []float64) []float64 {
 	if len(x) != len(y) {
 		panic("input length mismatch")
 	}
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, v := range x {
 		out[i] = v + y[i]*s
 	}
@@ -48,7 +49,7 @@ func subtract(x, y, out []float64) []float64 {
 	if len(x) != len(y) {
 		panic("input length mismatch")
 	}
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, v := range x {
 		out[i] = v - y[i]
 	}
@@ -65,7 +66,7 @@ func multiply(x, y, out []float64) []float64 {
 	if len(x) != len(y) {
 		panic("input length mismatch")
 	}
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, v := range x {
 		out[i] = v * y[i]
 	}
@@ -74,7 +75,7 @@ func multiply(x, y, out []float64) []float64 {
 
 func dot(x, y []float64) float64 {
 	if len(x) != len(y) {
-		panic(errLengths)
+		panic(ErrLengths)
 	}
 	d := 0.0
 	for i, v := range x {
@@ -85,7 +86,7 @@ func dot(x, y []float64) float64 {
 
 func swap(x, y []float64) {
 	if len(x) != len(y) {
-		panic(errLengths)
+		panic(ErrLengths)
 	}
 	for i := range x {
 		x[i], y[i] = y[i], x[i]
@@ -115,7 +116,7 @@ func zero(x []float64) {
 // out can be x itself, in which case elements
 // of x are incremented by the amount v.
 func shift(x []float64, v float64, out []float64) []float64 {
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, val := range x {
 		out[i] = val + v
 	}
@@ -129,7 +130,7 @@ func shift(x []float64, v float64, out []float64) []float64 {
 // out can be x itself, in which case elements
 // of x are scaled by the amount v.
 func scale(x []float64, v float64, out []float64) []float64 {
-	out = use_slice(out, len(x), errOutLength)
+	out = use_slice(out, len(x), ErrOutLength)
 	for i, val := range x {
 		out[i] = val * v
 	}
@@ -255,6 +256,22 @@ func use_slice(x []float64, n int, err error) []float64 {
 	return x
 }
 
+// try_use_dense takes a Dense x and required shape,
+// returns x if it is of correct shape,
+// returns a newly created Dense if x is nil,
+// and returns a *ShapeError wrapping ErrOutShape
+// if x is non-nil but has wrong shape.
+func try_use_dense(op string, x *Dense, r, c int) (*Dense, error) {
+	if x == nil {
+		return NewDense(r, c), nil
+	}
+	m, n := x.Dims()
+	if m != r || n != c {
+		return nil, &ShapeError{op, ErrOutShape, [][2]int{{r, c}, {m, n}}}
+	}
+	return x, nil
+}
+
 // use_dense takes a Dense x and required shape,
 // returns x if it is of correct shape,
 // returns a newly created Dense if x is nil,
@@ -270,6 +287,15 @@ func use_dense(x *Dense, r, c int, err error) *Dense {
 	return x
 }
 
+// must_dense panics if e is not nil, otherwise returns m.
+// It turns a Try function into its panicking counterpart.
+func must_dense(m *Dense, e error) *Dense {
+	if e != nil {
+		panic(e)
+	}
+	return m
+}
+
 func eye(k int) *Dense {
 	x := NewDense(k, k)
 	x.FillDiag(1.0)
@@ -282,23 +308,65 @@ type err string
 
 func (e err) Error() string { return "dense: " + string(e) }
 
+// Errors reported by the package.
+// The Try functions return these, possibly wrapped in a *ShapeError;
+// test for them with errors.Is.
+// The panicking functions panic with the same values.
 const (
-	errIndexOutOfRange = err("index out of range")
-	errZeroLength      = err("zero length in matrix definition")
-	errRowLength       = err("row length mismatch")
-	errColLength       = err("col length mismatch")
-	errSquare          = err("expect square matrix")
-	errNormOrder       = err("invalid norm order for matrix")
-	errSingular        = err("matrix is singular")
-	errIllegalStride   = err("illegal stride")
-	errPivot           = err("malformed pivot list")
-	errIllegalOrder    = err("illegal order")
-	errNoEngine        = err("no blas engine registered: call Register()")
-	errInLength        = err("input slice has wrong length")
-	errInShape         = err("input matrix has wrong shape")
-	errOutLength       = err("output slice has wrong length")
-	errOutShape        = err("output matrix has wrong shape")
-	errLengths         = err("length mismatch")
-	errShapes          = err("shape mismatch")
-	errInNil           = err("input is nil")
+	ErrIndexOutOfRange = err("index out of range")
+	ErrZeroLength      = err("zero length in matrix definition")
+	ErrRowLength       = err("row length mismatch")
+	ErrColLength       = err("col length mismatch")
+	ErrSquare          = err("expect square matrix")
+	ErrNormOrder       = err("invalid norm order for matrix")
+	ErrSingular        = err("matrix is singular")
+	ErrIllegalStride   = err("illegal stride")
+	ErrPivot           = err("malformed pivot list")
+	ErrIllegalOrder    = err("illegal order")
+	ErrInLength        = err("input slice has wrong length")
+	ErrInShape         = err("input matrix has wrong shape")
+	ErrOutLength       = err("output slice has wrong length")
+	ErrOutShape        = err("output matrix has wrong shape")
+	ErrLengths         = err("length mismatch")
+	ErrShapes          = err("shape mismatch")
+	ErrInNil           = err("input is nil")
+	ErrRankDeficient   = err("matrix is rank deficient")
+	ErrNotPosDef       = err("matrix is not positive definite")
+	ErrFormat          = err("malformed input data")
 )
+
+// ShapeError reports operands whose dimensions do not agree.
+// Err is the sentinel describing the problem, e.g. ErrShapes or
+// ErrOutShape, and is what errors.Is matches against.
+// Dims lists the (rows, cols) of the operands involved, in argument
+// order; for ErrOutShape, the required shape comes first, followed by
+// the shape of the output matrix that was passed in.
+type ShapeError struct {
+	Op   string
+	Err  error
+	Dims [][2]int
+}
+
+func (e *ShapeError) Error() string {
+	s := e.Err.Error() + " in " + e.Op + ":"
+	for i, d := range e.Dims {
+		if i > 0 {
+			s += ","
+		}
+		s += " " + strconv.Itoa(d[0]) + "x" + strconv.Itoa(d[1])
+	}
+	return s
+}
+
+func (e *ShapeError) Unwrap() error { return e.Err }
+
+// shape_error creates a *ShapeError recording the shapes
+// of the matrices in ms.
+func shape_error(op string, e error, ms ...Matrix) *ShapeError {
+	dims := make([][2]int, len(ms))
+	for i, m := range ms {
+		r, c := m.Dims()
+		dims[i] = [2]int{r, c}
+	}
+	return &ShapeError{op, e, dims}
+}
@@ -6,11 +6,11 @@ import (
 	"math/rand"
 )
 
-func isUpperTriangular(a *Dense) bool {
+func isUpperTriangular(a Matrix) bool {
 	rows, cols := a.Dims()
 	for c := 0; c < cols-1; c++ {
 		for r := c + 1; r < rows; r++ {
-			if math.Abs(a.Get(r, c)) > 1e-14 {
+			if math.Abs(a.At(r, c)) > 1e-14 {
 				return false
 			}
 		}
@@ -60,7 +60,7 @@ func make_dense(r, c int, data []float64) *Dense {
 
 func randDense(size int, rho float64, rnd func() float64) (*Dense, error) {
 	if size == 0 {
-		return nil, errZeroLength
+		return nil, ErrZeroLength
 	}
 	d := &Dense{
 		rows: size, cols: size, stride: size,
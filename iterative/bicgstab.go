@@ -0,0 +1,67 @@
+package iterative
+
+// BiCGSTAB solves a * x = b by the stabilized biconjugate gradient
+// method of van der Vorst, where a may be any nonsingular matrix.
+// Unlike GMRES, it uses a fixed amount of memory, but its
+// convergence is not monotone, and it may break down.
+// Each iteration takes two products with a.
+//
+// The error is non-nil only for invalid input.
+func BiCGSTAB(a Operator, b []float64, settings *Settings) (*Result, error) {
+	s, e := new_solver(a, b, settings)
+	if e != nil {
+		return nil, e
+	}
+	n := len(b)
+	x := s.res.X
+	r := s.residual(x, make([]float64, n))
+	if s.start(norm(r)) {
+		return s.res, nil
+	}
+	rhat := append([]float64(nil), r...)
+	p := make([]float64, n)
+	v := make([]float64, n)
+	t := make([]float64, n)
+	rho, alpha, omega := 1.0, 1.0, 1.0
+
+	for s.iterate() {
+		rhoNew := dot(rhat, r)
+		if rhoNew == 0 || omega == 0 {
+			s.res.Stop = Breakdown
+			break
+		}
+		beta := (rhoNew / rho) * (alpha / omega)
+		rho = rhoNew
+		for i, ri := range r {
+			p[i] = ri + beta*(p[i]-omega*v[i])
+		}
+		a.MultVec(p, v)
+		rv := dot(rhat, v)
+		if rv == 0 {
+			s.res.Stop = Breakdown
+			break
+		}
+		alpha = rho / rv
+		axpy(alpha, p, x)
+		axpy(-alpha, v, r) // r is now s of the textbook
+		if rn := norm(r); rn <= s.tol {
+			s.record(rn)
+			s.res.Stop = Converged
+			break
+		}
+		a.MultVec(r, t)
+		tt := dot(t, t)
+		if tt == 0 {
+			s.res.Stop = Breakdown
+			break
+		}
+		omega = dot(t, r) / tt
+		axpy(omega, r, x)
+		axpy(-omega, t, r)
+		if s.record(norm(r)) {
+			s.res.Stop = Converged
+			break
+		}
+	}
+	return s.res, nil
+}
@@ -0,0 +1,20 @@
+package iterative
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestBiCGSTAB(c *check.C) {
+	a := convection(20)
+	b := rand_vec(400)
+	res, e := BiCGSTAB(a, b, &Settings{Tol: 1e-10})
+	c.Check(e, check.IsNil)
+	check_solution(c, a, b, res, 1e-9)
+
+	// A rotation by 90 degrees: rhat'v = 0 in the first iteration.
+	res, _ = BiCGSTAB(OperatorFunc(func(x, out []float64) []float64 {
+		out[0], out[1] = x[1], -x[0]
+		return out
+	}), []float64{1, 0}, nil)
+	c.Check(res.Stop, check.Not(check.Equals), Converged)
+}
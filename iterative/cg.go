@@ -0,0 +1,66 @@
+package iterative
+
+// CG solves a * x = b by the conjugate gradient method, where a is
+// symmetric positive definite. It stops with Breakdown if a turns out
+// not to be positive definite.
+//
+// The error is non-nil only for invalid input.
+func CG(a Operator, b []float64, settings *Settings) (*Result, error) {
+	return PCG(a, b, nil, settings)
+}
+
+// PCG solves a * x = b by the preconditioned conjugate gradient method,
+// where a and the preconditioner m are symmetric positive definite.
+// A nil m means no preconditioning, as in CG.
+//
+// The error is non-nil only for invalid input.
+func PCG(a Operator, b []float64, m Preconditioner, settings *Settings) (*Result, error) {
+	s, e := new_solver(a, b, settings)
+	if e != nil {
+		return nil, e
+	}
+	n := len(b)
+	x := s.res.X
+	r := s.residual(x, make([]float64, n))
+	if s.start(norm(r)) {
+		return s.res, nil
+	}
+	z := r
+	if m != nil {
+		z = m.Solve(r, make([]float64, n))
+	}
+	p := append([]float64(nil), z...)
+	ap := make([]float64, n)
+	rz := dot(r, z)
+
+	for s.iterate() {
+		a.MultVec(p, ap)
+		pap := dot(p, ap)
+		if pap <= 0 {
+			s.res.Stop = Breakdown
+			break
+		}
+		alpha := rz / pap
+		axpy(alpha, p, x)
+		axpy(-alpha, ap, r)
+		if s.record(norm(r)) {
+			s.res.Stop = Converged
+			break
+		}
+		if m != nil {
+			m.Solve(r, z)
+		}
+		rzNew := dot(r, z)
+		if rzNew <= 0 {
+			// m is not positive definite.
+			s.res.Stop = Breakdown
+			break
+		}
+		beta := rzNew / rz
+		rz = rzNew
+		for i, v := range z {
+			p[i] = v + beta*p[i]
+		}
+	}
+	return s.res, nil
+}
@@ -0,0 +1,23 @@
+package iterative
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestCG(c *check.C) {
+	a := laplacian(20, 0)
+	b := rand_vec(400)
+	res, e := CG(a, b, &Settings{Tol: 1e-10})
+	c.Check(e, check.IsNil)
+	check_solution(c, a, b, res, 1e-9)
+
+	// In exact arithmetic, at most n iterations.
+	a = laplacian(3, 0)
+	res, _ = CG(a, rand_vec(9), &Settings{Tol: 1e-12})
+	c.Check(res.Stop, check.Equals, Converged)
+	c.Check(res.Iterations <= 9, check.Equals, true)
+
+	// An indefinite matrix.
+	res, _ = CG(laplacian(5, -4), rand_vec(25), nil)
+	c.Check(res.Stop, check.Equals, Breakdown)
+}
@@ -0,0 +1,120 @@
+package iterative
+
+import (
+	"math"
+)
+
+// GMRES solves a * x = b by the generalized minimum residual method,
+// restarted every Settings.Restart iterations to bound the memory and
+// work per iteration. a may be any nonsingular matrix. The Krylov basis
+// is orthonormalized by modified Gram-Schmidt, and the least squares
+// problem is solved by Givens rotations.
+//
+// The error is non-nil only for invalid input.
+func GMRES(a Operator, b []float64, settings *Settings) (*Result, error) {
+	s, e := new_solver(a, b, settings)
+	if e != nil {
+		return nil, e
+	}
+	n, m := len(b), s.restart
+	x := s.res.X
+	r := s.residual(x, make([]float64, n))
+	beta := norm(r)
+	if s.start(beta) {
+		return s.res, nil
+	}
+
+	vs := make([][]float64, m+1) // the basis
+	for i := range vs {
+		vs[i] = make([]float64, n)
+	}
+	h := make([][]float64, m) // h[j] is col j of the Hessenberg matrix
+	for j := range h {
+		h[j] = make([]float64, j+2)
+	}
+	cs := make([]float64, m)
+	sn := make([]float64, m)
+	g := make([]float64, m+1) // the rotated right-hand side
+
+	for {
+		for i, v := range r {
+			vs[0][i] = v / beta
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = beta
+
+		// The Arnoldi process, up to m steps.
+		k, done := 0, false
+		for k < m && !done {
+			if !s.iterate() {
+				done = true
+				break
+			}
+			w := vs[k+1]
+			a.MultVec(vs[k], w)
+			hk := h[k]
+			for i := 0; i <= k; i++ {
+				hk[i] = dot(w, vs[i])
+				axpy(-hk[i], vs[i], w)
+			}
+			hk[k+1] = norm(w)
+			if hk[k+1] != 0 {
+				for i := range w {
+					w[i] /= hk[k+1]
+				}
+			}
+
+			// Apply the previous rotations to the new col, and
+			// a new one to eliminate h[k+1][k].
+			for i := 0; i < k; i++ {
+				hk[i], hk[i+1] = cs[i]*hk[i]+sn[i]*hk[i+1], -sn[i]*hk[i]+cs[i]*hk[i+1]
+			}
+			d := math.Hypot(hk[k], hk[k+1])
+			if d == 0 {
+				s.res.Stop = Breakdown
+				done = true
+				break
+			}
+			cs[k], sn[k] = hk[k]/d, hk[k+1]/d
+			hk[k], hk[k+1] = d, 0
+			g[k], g[k+1] = cs[k]*g[k], -sn[k]*g[k]
+			k++
+
+			// If hk[k+1] was zero, the Krylov subspace is invariant,
+			// and the residual is zero now.
+			if s.record(math.Abs(g[k])) {
+				s.res.Stop = Converged
+				done = true
+			}
+		}
+
+		// Update x by the solution of the k by k triangular system.
+		y := g[:k]
+		for i := k - 1; i >= 0; i-- {
+			for j := i + 1; j < k; j++ {
+				y[i] -= h[j][i] * y[j]
+			}
+			y[i] /= h[i][i]
+		}
+		for j := 0; j < k; j++ {
+			axpy(y[j], vs[j], x)
+		}
+		if done {
+			break
+		}
+
+		// Restart from the true residual, which replaces the
+		// estimate in the history.
+		s.residual(x, r)
+		beta = norm(r)
+		s.res.ResidualNorm = beta
+		s.res.History[len(s.res.History)-1] = beta
+		if beta <= s.tol {
+			s.res.Stop = Converged
+			break
+		}
+	}
+	return s.res, nil
+}
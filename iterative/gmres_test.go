@@ -0,0 +1,26 @@
+package iterative
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestGMRES(c *check.C) {
+	a := convection(20)
+	b := rand_vec(400)
+	for _, restart := range []int{5, 30, 400} {
+		res, e := GMRES(a, b, &Settings{Tol: 1e-10, Restart: restart, MaxIter: 10000})
+		c.Check(e, check.IsNil)
+		check_solution(c, a, b, res, 1e-9)
+	}
+
+	// Without restarts, the residual norm does not increase,
+	// and there are at most n iterations.
+	a = convection(4)
+	b = rand_vec(16)
+	res, _ := GMRES(a, b, &Settings{Tol: 1e-12, Restart: 16})
+	check_solution(c, a, b, res, 1e-11)
+	c.Check(res.Iterations <= 16, check.Equals, true)
+	for i := 1; i < len(res.History); i++ {
+		c.Check(res.History[i] <= res.History[i-1]*(1+1e-12), check.Equals, true)
+	}
+}
@@ -0,0 +1,126 @@
+package iterative
+
+import (
+	"math"
+
+	"github.com/gonum/blas"
+	"github.com/zpz/matrix.go/dense"
+)
+
+// IC0 is the incomplete Cholesky factorization with no fill,
+//    m = l * l'
+// where l is lower triangular with the pattern of the lower triangle
+// of a, and m agrees with a on that pattern.
+type IC0 struct {
+	l *dense.TriDense
+}
+
+// NewIC0 computes the IC(0) preconditioner for the symmetric positive
+// definite a, of which only the lower triangle is read. It fails with
+// ErrBreakdown if a nonpositive pivot comes up, which may happen even
+// if a is positive definite; an M-matrix is safe.
+func NewIC0(a *dense.Dense) (*IC0, error) {
+	n, c := a.Dims()
+	if n != c {
+		return nil, &dense.ShapeError{Op: "NewIC0", Err: dense.ErrSquare, Dims: [][2]int{{n, c}}}
+	}
+	l := dense.NewDense(n, n)
+	dense.CopyLower(l, a)
+	dense.CopyDiag(l, a)
+
+	for k := 0; k < n; k++ {
+		d := l.Get(k, k)
+		if d <= 0 {
+			return nil, ErrBreakdown
+		}
+		d = math.Sqrt(d)
+		l.Set(k, k, d)
+		for i := k + 1; i < n; i++ {
+			if a.Get(i, k) != 0 {
+				l.Set(i, k, l.Get(i, k)/d)
+			}
+		}
+		// Update the trailing matrix within the pattern.
+		for j := k + 1; j < n; j++ {
+			ljk := l.Get(j, k)
+			if ljk == 0 {
+				continue
+			}
+			for i := j; i < n; i++ {
+				if a.Get(i, j) != 0 {
+					l.Set(i, j, l.Get(i, j)-l.Get(i, k)*ljk)
+				}
+			}
+		}
+	}
+	return &IC0{dense.TriDenseView(l, blas.Lower, blas.NonUnit)}, nil
+}
+
+// L returns the factor l.
+func (p *IC0) L() *dense.TriDense { return p.l }
+
+func (p *IC0) Solve(x, out []float64) []float64 {
+	copy(out, x)
+	z := dense.DenseView(out, len(out), 1)
+	dense.SolveTri(p.l, false, z)
+	dense.SolveTri(p.l, true, z)
+	return out
+}
+
+// ILU0 is the incomplete LU factorization with no fill,
+//    m = l * u
+// where l is unit lower triangular and u upper triangular, with the
+// pattern of a, and m agrees with a on that pattern.
+type ILU0 struct {
+	lu *dense.Dense
+}
+
+// NewILU0 computes the ILU(0) preconditioner for a. There is no
+// pivoting; it fails with ErrBreakdown if a zero pivot comes up.
+func NewILU0(a *dense.Dense) (*ILU0, error) {
+	n, c := a.Dims()
+	if n != c {
+		return nil, &dense.ShapeError{Op: "NewILU0", Err: dense.ErrSquare, Dims: [][2]int{{n, c}}}
+	}
+	lu := dense.Clone(a)
+
+	// The IKJ variant of Gaussian elimination, restricted to the pattern.
+	for i := 1; i < n; i++ {
+		rowi := lu.RowView(i)
+		for k := 0; k < i; k++ {
+			if a.Get(i, k) == 0 {
+				continue
+			}
+			rowk := lu.RowView(k)
+			if rowk[k] == 0 {
+				return nil, ErrBreakdown
+			}
+			rowi[k] /= rowk[k]
+			for j := k + 1; j < n; j++ {
+				if a.Get(i, j) != 0 {
+					rowi[j] -= rowi[k] * rowk[j]
+				}
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if lu.Get(i, i) == 0 {
+			return nil, ErrBreakdown
+		}
+	}
+	return &ILU0{lu}, nil
+}
+
+// L returns the unit lower triangular factor l.
+func (p *ILU0) L() *dense.TriDense { return dense.TriDenseView(p.lu, blas.Lower, blas.Unit) }
+
+// U returns the upper triangular factor u.
+func (p *ILU0) U() *dense.TriDense { return dense.TriDenseView(p.lu, blas.Upper, blas.NonUnit) }
+
+func (p *ILU0) Solve(x, out []float64) []float64 {
+	copy(out, x)
+	z := dense.DenseView(out, len(out), 1)
+	dense.SolveTri(p.L(), false, z)
+	dense.SolveTri(p.U(), false, z)
+	return out
+}
@@ -0,0 +1,79 @@
+package iterative
+
+import (
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestIC0(c *check.C) {
+	a := laplacian(8, 0).Full(nil)
+	b := rand_vec(64)
+	plain := check_precond(c, a, nil, b)
+	p, e := NewIC0(a)
+	c.Assert(e, check.IsNil)
+	it := check_precond(c, a, p, b)
+	c.Check(it < plain, check.Equals, true, check.Commentf("IC0 %d, none %d", it, plain))
+
+	// l has the pattern of a, and l * l' agrees with a on it.
+	l := p.L()
+	llt := dense.Mult(l, l.TView(), nil)
+	for i := 0; i < 64; i++ {
+		for j := 0; j <= i; j++ {
+			if a.Get(i, j) == 0 {
+				c.Check(l.At(i, j), check.Equals, 0.0)
+			} else {
+				c.Check(llt.Get(i, j)-a.Get(i, j) < 1e-12 && a.Get(i, j)-llt.Get(i, j) < 1e-12,
+					check.Equals, true)
+			}
+		}
+	}
+
+	// Without fill, e.g. for a tridiagonal matrix, IC(0) is exact.
+	t := dense.DenseView([]float64{4, 1, 0, 1, 4, 1, 0, 1, 4}, 3, 3)
+	p, _ = NewIC0(t)
+	x := []float64{1, 2, 3}
+	z := p.Solve(DenseOperator(t).MultVec(x, nil), make([]float64, 3))
+	c.Check(dense.Approx(dense.DenseView(z, 3, 1), dense.DenseView(x, 3, 1), 1e-12), check.Equals, true)
+
+	_, e = NewIC0(dense.DenseView([]float64{1, 2, 2, 1}, 2, 2))
+	c.Check(e, check.Equals, ErrBreakdown)
+}
+
+func (s *S) TestILU0(c *check.C) {
+	a := convection(8).Full(nil)
+	p, e := NewILU0(a)
+	c.Assert(e, check.IsNil)
+
+	// l * u agrees with a on its pattern.
+	lu := dense.Mult(p.L(), p.U(), nil)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 64; j++ {
+			if a.Get(i, j) != 0 {
+				d := lu.Get(i, j) - a.Get(i, j)
+				c.Check(d < 1e-12 && d > -1e-12, check.Equals, true)
+			} else if i > j {
+				c.Check(p.L().At(i, j), check.Equals, 0.0)
+			} else {
+				c.Check(p.U().At(i, j), check.Equals, 0.0)
+			}
+		}
+	}
+
+	// On a symmetric matrix, ILU(0) is symmetric, and serves PCG.
+	a = laplacian(8, 0).Full(nil)
+	b := rand_vec(64)
+	plain := check_precond(c, a, nil, b)
+	p, _ = NewILU0(a)
+	it := check_precond(c, a, p, b)
+	c.Check(it < plain, check.Equals, true, check.Commentf("ILU0 %d, none %d", it, plain))
+
+	// Exact without fill.
+	t := dense.DenseView([]float64{4, 1, 0, 2, 4, 1, 0, 3, 4}, 3, 3)
+	p, _ = NewILU0(t)
+	x := []float64{1, 2, 3}
+	z := p.Solve(DenseOperator(t).MultVec(x, nil), make([]float64, 3))
+	c.Check(dense.Approx(dense.DenseView(z, 3, 1), dense.DenseView(x, 3, 1), 1e-12), check.Equals, true)
+
+	_, e = NewILU0(dense.DenseView([]float64{0, 1, 1, 0}, 2, 2))
+	c.Check(e, check.Equals, ErrBreakdown)
+}
@@ -0,0 +1,214 @@
+// Package iterative provides Krylov subspace methods for solving
+// large linear systems a * x = b, which need a only through products
+// with vectors:
+//    CG        conjugate gradient, for symmetric positive definite a
+//    MINRES    minimum residual, for symmetric a
+//    GMRES     restarted generalized minimum residual, for any a
+//    BiCGSTAB  stabilized biconjugate gradient, for any a
+//
+// The matrix is passed as an Operator, which *sparse.CSR and
+// *sparse.CSC implement; DenseOperator and OperatorFunc adapt
+// other representations.
+//
+// PCG takes a Preconditioner; Jacobi, SSOR, IC0 and ILU0 are built
+// from a *dense.Dense.
+package iterative
+
+import (
+	"math"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+// Operator is a linear operator a, known by its action on vectors.
+type Operator interface {
+	// MultVec computes a * x into out, and returns out.
+	// The solvers always pass an out of the correct length,
+	// which does not share storage with x.
+	MultVec(x, out []float64) []float64
+}
+
+// OperatorFunc adapts a function to an Operator.
+type OperatorFunc func(x, out []float64) []float64
+
+func (f OperatorFunc) MultVec(x, out []float64) []float64 { return f(x, out) }
+
+// DenseOperator returns m as an Operator.
+func DenseOperator(m *dense.Dense) Operator {
+	r, c := m.Dims()
+	return OperatorFunc(func(x, out []float64) []float64 {
+		if out == nil {
+			out = make([]float64, r)
+		}
+		dense.Mult(m, dense.DenseView(x, c, 1), dense.DenseView(out, r, 1))
+		return out
+	})
+}
+
+// Settings control the iteration. The zero value of a field, or a nil
+// *Settings, selects the default.
+type Settings struct {
+	// Tol is the tolerance on the relative residual:
+	// iteration stops once |b - a * x| <= Tol * |b|.
+	// The default is 1e-8.
+	Tol float64
+
+	// MaxIter is the maximum number of iterations, each of which
+	// takes one product with a (two for BiCGSTAB).
+	// The default is 10 times the length of b.
+	MaxIter int
+
+	// Restart is the number of iterations between restarts of GMRES.
+	// The default is 30, or the length of b if smaller.
+	Restart int
+
+	// X0 is the initial guess, which is not changed.
+	// The default is zero.
+	X0 []float64
+}
+
+// Stop tells why iteration stopped.
+type Stop int
+
+const (
+	// Converged means that the tolerance has been reached.
+	Converged Stop = iota
+	// MaxIterations means that the iteration limit was reached first.
+	MaxIterations
+	// Breakdown means that the method can not proceed, e.g. CG on
+	// a matrix that is not positive definite.
+	Breakdown
+)
+
+func (s Stop) String() string {
+	switch s {
+	case Converged:
+		return "converged"
+	case MaxIterations:
+		return "maximum iterations reached"
+	case Breakdown:
+		return "breakdown"
+	}
+	return "unknown"
+}
+
+// Result is the outcome of a solve.
+type Result struct {
+	X []float64
+
+	// Iterations is the number of iterations done.
+	Iterations int
+
+	// ResidualNorm is the norm of the residual b - a * X, as tracked
+	// by the method, which may differ from the true residual by
+	// rounding errors.
+	ResidualNorm float64
+
+	// History holds the residual norm at the start and after
+	// each iteration.
+	History []float64
+
+	Stop Stop
+}
+
+// solver is the state common to the methods.
+type solver struct {
+	a       Operator
+	b       []float64
+	tol     float64 // absolute, that is, Settings.Tol * |b|
+	maxIter int
+	restart int
+	res     *Result
+}
+
+// new_solver checks the input, and sets up the solver with the
+// initial guess in res.X.
+func new_solver(a Operator, b []float64, s *Settings) (*solver, error) {
+	var set Settings
+	if s != nil {
+		set = *s
+	}
+	n := len(b)
+	if set.X0 != nil && len(set.X0) != n {
+		return nil, dense.ErrInLength
+	}
+	if set.Tol <= 0 {
+		set.Tol = 1e-8
+	}
+	if set.MaxIter <= 0 {
+		set.MaxIter = 10 * n
+	}
+	if set.Restart <= 0 {
+		set.Restart = 30
+	}
+	if set.Restart > n {
+		set.Restart = n
+	}
+	x := make([]float64, n)
+	copy(x, set.X0)
+	return &solver{
+		a:       a,
+		b:       b,
+		tol:     set.Tol * norm(b),
+		maxIter: set.MaxIter,
+		restart: set.Restart,
+		res:     &Result{X: x},
+	}, nil
+}
+
+// residual computes r = b - a * x.
+func (s *solver) residual(x, r []float64) []float64 {
+	s.a.MultVec(x, r)
+	for i, v := range s.b {
+		r[i] = v - r[i]
+	}
+	return r
+}
+
+// record records the residual norm rn after an iteration, and
+// reports whether it meets the tolerance.
+func (s *solver) record(rn float64) bool {
+	s.res.ResidualNorm = rn
+	s.res.History = append(s.res.History, rn)
+	return rn <= s.tol
+}
+
+// start records the initial residual norm, and reports whether
+// x is a solution already.
+func (s *solver) start(rn float64) bool {
+	if s.record(rn) {
+		s.res.Stop = Converged
+		return true
+	}
+	return false
+}
+
+// iterate counts an iteration and reports true, unless the limit
+// has been reached, in which case it sets the stop reason.
+func (s *solver) iterate() bool {
+	if s.res.Iterations >= s.maxIter {
+		s.res.Stop = MaxIterations
+		return false
+	}
+	s.res.Iterations++
+	return true
+}
+
+func dot(x, y []float64) float64 {
+	v := 0.0
+	for i, xx := range x {
+		v += xx * y[i]
+	}
+	return v
+}
+
+func norm(x []float64) float64 {
+	return math.Sqrt(dot(x, x))
+}
+
+// axpy adds s * x to y.
+func axpy(s float64, x, y []float64) {
+	for i, v := range x {
+		y[i] += s * v
+	}
+}
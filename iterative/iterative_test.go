@@ -0,0 +1,124 @@
+package iterative
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zpz/matrix.go/dense"
+	"github.com/zpz/matrix.go/sparse"
+	check "launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+// laplacian returns the 5-point Laplacian on an n by n grid,
+// which is symmetric positive definite, plus shift on the diagonal.
+func laplacian(n int, shift float64) *sparse.CSR {
+	coo := sparse.NewCOO(n*n, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			k := i*n + j
+			coo.Append(k, k, 4+shift)
+			if i > 0 {
+				coo.Append(k, k-n, -1).Append(k-n, k, -1)
+			}
+			if j > 0 {
+				coo.Append(k, k-1, -1).Append(k-1, k, -1)
+			}
+		}
+	}
+	return coo.ToCSR()
+}
+
+// convection returns a nonsymmetric matrix: the Laplacian plus
+// an upwind convection term.
+func convection(n int) *sparse.CSR {
+	a := laplacian(n, 0)
+	coo := sparse.NewCOO(n*n, n*n)
+	for k := 0; k < n*n; k++ {
+		coo.Append(k, k, 1)
+		if k > 0 {
+			coo.Append(k, k-1, -1)
+		}
+	}
+	return a.Add(coo.ToCSR())
+}
+
+func rand_vec(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rand.NormFloat64()
+	}
+	return x
+}
+
+// check_solution checks that res solves a * x = b to the relative
+// tolerance tol, judging by the true residual.
+func check_solution(c *check.C, a Operator, b []float64, res *Result, tol float64) {
+	c.Check(res.Stop, check.Equals, Converged)
+	r := a.MultVec(res.X, make([]float64, len(b)))
+	for i := range r {
+		r[i] -= b[i]
+	}
+	c.Check(norm(r) <= tol*norm(b), check.Equals, true,
+		check.Commentf("residual %g, |b| %g", norm(r), norm(b)))
+	c.Check(len(res.History), check.Equals, res.Iterations+1)
+	c.Check(res.History[len(res.History)-1], check.Equals, res.ResidualNorm)
+}
+
+type method func(Operator, []float64, *Settings) (*Result, error)
+
+var methods = map[string]method{
+	"CG": CG, "MINRES": MINRES, "GMRES": GMRES, "BiCGSTAB": BiCGSTAB,
+}
+
+func (s *S) TestCommon(c *check.C) {
+	a := laplacian(5, 0)
+	for name, m := range methods {
+		comment := check.Commentf(name)
+
+		// Zero right-hand side.
+		res, e := m(a, make([]float64, 25), nil)
+		c.Check(e, check.IsNil, comment)
+		c.Check(res.Stop, check.Equals, Converged, comment)
+		c.Check(res.Iterations, check.Equals, 0, comment)
+		c.Check(res.X, check.DeepEquals, make([]float64, 25), comment)
+
+		// The exact solution as initial guess.
+		x := rand_vec(25)
+		b := a.MultVec(x, nil)
+		res, _ = m(a, b, &Settings{X0: x})
+		c.Check(res.Iterations, check.Equals, 0, comment)
+		c.Check(res.Stop, check.Equals, Converged, comment)
+
+		// The initial guess is not changed.
+		x0 := make([]float64, 25)
+		x0[3] = 1
+		res, _ = m(a, b, &Settings{X0: x0})
+		c.Check(x0[3], check.Equals, 1.0, comment)
+		check_solution(c, a, b, res, 1e-8)
+
+		res, _ = m(a, b, &Settings{MaxIter: 2})
+		c.Check(res.Stop, check.Equals, MaxIterations, comment)
+		c.Check(res.Iterations, check.Equals, 2, comment)
+
+		_, e = m(a, b, &Settings{X0: make([]float64, 3)})
+		c.Check(errors.Is(e, dense.ErrInLength), check.Equals, true, comment)
+	}
+	c.Check(Breakdown.String(), check.Equals, "breakdown")
+}
+
+func (s *S) TestDenseOperator(c *check.C) {
+	m := dense.DenseView([]float64{4, 1, 1, 3}, 2, 2)
+	res, e := CG(DenseOperator(m), []float64{1, 2}, &Settings{Tol: 1e-12})
+	c.Check(e, check.IsNil)
+	c.Check(res.Iterations <= 2, check.Equals, true)
+	c.Check(math.Abs(res.X[0]-1.0/11) < 1e-12, check.Equals, true)
+	c.Check(math.Abs(res.X[1]-7.0/11) < 1e-12, check.Equals, true)
+}
@@ -0,0 +1,82 @@
+package iterative
+
+import (
+	"math"
+)
+
+// MINRES solves a * x = b by the minimum residual method of Paige
+// and Saunders, where a is symmetric, possibly indefinite. Each
+// iteration minimizes the residual norm over the Krylov subspace,
+// which is built by the Lanczos process.
+//
+// The error is non-nil only for invalid input.
+func MINRES(a Operator, b []float64, settings *Settings) (*Result, error) {
+	s, e := new_solver(a, b, settings)
+	if e != nil {
+		return nil, e
+	}
+	n := len(b)
+	x := s.res.X
+
+	// The Lanczos vectors: r2 is the latest, r1 the one before,
+	// each scaled by the beta that normalizes it.
+	r1 := s.residual(x, make([]float64, n))
+	r2 := append([]float64(nil), r1...)
+	y := make([]float64, n)
+	v := make([]float64, n)
+	beta := norm(r1)
+	if s.start(beta) {
+		return s.res, nil
+	}
+
+	// The QR factorization of the tridiagonal Lanczos matrix by
+	// Givens rotations (cs, sn), and the search directions w.
+	var oldb, dbar, epsln float64
+	phibar := beta
+	cs, sn := -1.0, 0.0
+	w := make([]float64, n)
+	w1 := make([]float64, n)
+	w2 := make([]float64, n)
+
+	for s.iterate() {
+		for i := range v {
+			v[i] = r2[i] / beta
+		}
+		a.MultVec(v, y)
+		if s.res.Iterations > 1 {
+			axpy(-beta/oldb, r1, y)
+		}
+		alfa := dot(v, y)
+		axpy(-alfa/beta, r2, y)
+		r1, r2, y = r2, y, r1
+		oldb, beta = beta, norm(r2)
+
+		oldeps := epsln
+		delta := cs*dbar + sn*alfa
+		gbar := sn*dbar - cs*alfa
+		epsln = sn * beta
+		dbar = -cs * beta
+		gamma := math.Hypot(gbar, beta)
+		if gamma == 0 {
+			s.res.Stop = Breakdown
+			break
+		}
+		cs, sn = gbar/gamma, beta/gamma
+		phi := cs * phibar
+		phibar *= sn
+
+		w1, w2, w = w2, w, w1
+		for i := range w {
+			w[i] = (v[i] - oldeps*w1[i] - delta*w2[i]) / gamma
+		}
+		axpy(phi, w, x)
+
+		if s.record(math.Abs(phibar)) || beta == 0 {
+			// beta == 0 means the Krylov subspace is invariant,
+			// hence x is exact.
+			s.res.Stop = Converged
+			break
+		}
+	}
+	return s.res, nil
+}
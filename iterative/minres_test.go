@@ -0,0 +1,23 @@
+package iterative
+
+import (
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestMINRES(c *check.C) {
+	// Indefinite: the spectrum of the Laplacian is in (0, 8).
+	a := laplacian(20, -4.1)
+	b := rand_vec(400)
+	res, e := MINRES(a, b, &Settings{Tol: 1e-10})
+	c.Check(e, check.IsNil)
+	check_solution(c, a, b, res, 1e-8)
+
+	// The residual norm does not increase.
+	for i := 1; i < len(res.History); i++ {
+		c.Check(res.History[i] <= res.History[i-1]*(1+1e-12), check.Equals, true)
+	}
+
+	a = laplacian(20, 0)
+	res, _ = MINRES(a, b, &Settings{Tol: 1e-10})
+	check_solution(c, a, b, res, 1e-8)
+}
@@ -0,0 +1,119 @@
+package iterative
+
+import (
+	"github.com/zpz/matrix.go/dense"
+)
+
+// Preconditioner is an approximation m of a matrix a, such that
+// systems m * z = x are cheap to solve, and m^{-1} * a is better
+// conditioned than a.
+type Preconditioner interface {
+	// Solve computes m^{-1} * x into out, and returns out.
+	// The solvers always pass an out of the correct length,
+	// which does not share storage with x.
+	Solve(x, out []float64) []float64
+}
+
+type err string
+
+func (e err) Error() string {
+	return "iterative: " + string(e)
+}
+
+const (
+	ErrOmega     = err("SSOR relaxation factor not in (0, 2)")
+	ErrBreakdown = err("incomplete factorization breaks down")
+)
+
+// square_diag returns the diagonal of the square matrix a, or an error
+// if a is not square or has a zero on the diagonal.
+func square_diag(op string, a *dense.Dense) ([]float64, error) {
+	r, c := a.Dims()
+	if r != c {
+		return nil, &dense.ShapeError{Op: op, Err: dense.ErrSquare, Dims: [][2]int{{r, c}}}
+	}
+	d := a.GetDiag(make([]float64, r))
+	for _, v := range d {
+		if v == 0 {
+			return nil, dense.ErrSingular
+		}
+	}
+	return d, nil
+}
+
+// Jacobi is the diagonal of a matrix as a preconditioner.
+type Jacobi struct {
+	inv []float64 // inverse of the diagonal
+}
+
+// NewJacobi creates the Jacobi preconditioner for a. It fails with
+// dense.ErrSingular if a has a zero on the diagonal.
+func NewJacobi(a *dense.Dense) (*Jacobi, error) {
+	d, e := square_diag("NewJacobi", a)
+	if e != nil {
+		return nil, e
+	}
+	for i, v := range d {
+		d[i] = 1 / v
+	}
+	return &Jacobi{d}, nil
+}
+
+func (p *Jacobi) Solve(x, out []float64) []float64 {
+	for i, v := range x {
+		out[i] = v * p.inv[i]
+	}
+	return out
+}
+
+// SSOR is the symmetric successive over-relaxation preconditioner
+//    m = w/(2-w) * (d/w + l) * (d/w)^{-1} * (d/w + u)
+// where a = l + d + u, l is strictly lower triangular, d diagonal,
+// and u strictly upper triangular. It is symmetric positive definite
+// if a is. With w = 1, it is symmetric Gauss-Seidel.
+type SSOR struct {
+	a     *dense.Dense
+	d     []float64
+	omega float64
+}
+
+// NewSSOR creates the SSOR preconditioner for a with the relaxation
+// factor omega, which must be in (0, 2). a is kept by reference.
+func NewSSOR(a *dense.Dense, omega float64) (*SSOR, error) {
+	if !(omega > 0 && omega < 2) {
+		return nil, ErrOmega
+	}
+	d, e := square_diag("NewSSOR", a)
+	if e != nil {
+		return nil, e
+	}
+	return &SSOR{a, d, omega}, nil
+}
+
+func (p *SSOR) Solve(x, out []float64) []float64 {
+	n, w := len(p.d), p.omega
+
+	// Forward sweep: (d/w + l) * y = x.
+	for i := 0; i < n; i++ {
+		row := p.a.RowView(i)
+		v := x[i]
+		for j := 0; j < i; j++ {
+			v -= row[j] * out[j]
+		}
+		out[i] = v * w / p.d[i]
+	}
+	// Scale: y = (2-w)/w * d/w * y.
+	for i := range out {
+		out[i] *= (2 - w) / w * p.d[i] / w
+	}
+	// Backward sweep: (d/w + u) * z = y.
+	for i := n - 1; i >= 0; i-- {
+		row := p.a.RowView(i)
+		v := out[i]
+		for j := i + 1; j < n; j++ {
+			v -= row[j] * out[j]
+		}
+		out[i] = v * w / p.d[i]
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package iterative
+
+import (
+	"math"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+// scaled_laplacian returns d * a * d for the Laplacian a on an n by n
+// grid and a diagonal d spanning several orders of magnitude, which is
+// badly conditioned but fixed by diagonal scaling.
+func scaled_laplacian(n int) *dense.Dense {
+	a := laplacian(n, 0).Full(nil)
+	d := make([]float64, n*n)
+	for i := range d {
+		d[i] = math.Pow(10, 3*float64(i%7)/6)
+	}
+	for i := range d {
+		for j := range d {
+			a.Set(i, j, a.Get(i, j)*d[i]*d[j])
+		}
+	}
+	return a
+}
+
+// check_precond checks that PCG with m solves a * x = b,
+// and returns the number of iterations.
+func check_precond(c *check.C, a *dense.Dense, m Preconditioner, b []float64) int {
+	res, e := PCG(DenseOperator(a), b, m, &Settings{Tol: 1e-10, MaxIter: 5000})
+	c.Check(e, check.IsNil)
+	check_solution(c, DenseOperator(a), b, res, 1e-9)
+	return res.Iterations
+}
+
+func (s *S) TestJacobi(c *check.C) {
+	a := scaled_laplacian(8)
+	b := rand_vec(64)
+	plain := check_precond(c, a, nil, b)
+
+	p, e := NewJacobi(a)
+	c.Assert(e, check.IsNil)
+	c.Check(p.Solve([]float64{2, 3}, make([]float64, 2))[:1], check.DeepEquals, []float64{2 / a.Get(0, 0)})
+	it := check_precond(c, a, p, b)
+	c.Check(it < plain/2, check.Equals, true, check.Commentf("Jacobi %d, none %d", it, plain))
+
+	_, e = NewJacobi(dense.DenseView([]float64{1, 1, 1, 0}, 2, 2))
+	c.Check(e, check.Equals, dense.ErrSingular)
+	_, e = NewJacobi(dense.NewDense(2, 3))
+	c.Check(e, check.ErrorMatches, ".*square.*")
+}
+
+func (s *S) TestSSOR(c *check.C) {
+	a := laplacian(8, 0).Full(nil)
+	b := rand_vec(64)
+	plain := check_precond(c, a, nil, b)
+	for _, w := range []float64{1, 1.5} {
+		p, e := NewSSOR(a, w)
+		c.Assert(e, check.IsNil)
+		it := check_precond(c, a, p, b)
+		c.Check(it < plain, check.Equals, true, check.Commentf("SSOR(%g) %d, none %d", w, it, plain))
+	}
+
+	// m * m^{-1} x = x, with m formed explicitly.
+	a = dense.DenseView([]float64{4, 1, 0, 1, 5, 2, 0, 2, 6}, 3, 3)
+	w := 1.2
+	p, _ := NewSSOR(a, w)
+	dw := dense.NewDense(3, 3)
+	dw.SetDiag([]float64{4 / w, 5 / w, 6 / w})
+	lo := dense.Clone(dw)
+	dense.CopyLower(lo, a)
+	up := dense.Clone(dw)
+	dense.CopyUpper(up, a)
+	dinv := dense.NewDense(3, 3)
+	dinv.SetDiag([]float64{w / 4, w / 5, w / 6})
+	m := dense.Mult(dense.Mult(lo, dinv, nil), up, nil).Scale(w / (2 - w))
+	x := []float64{1, -2, 3}
+	z := p.Solve(x, make([]float64, 3))
+	c.Check(dense.Approx(dense.Mult(m, dense.DenseView(z, 3, 1), nil),
+		dense.DenseView(x, 3, 1), 1e-12), check.Equals, true)
+
+	_, e := NewSSOR(a, 2)
+	c.Check(e, check.Equals, ErrOmega)
+}
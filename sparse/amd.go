@@ -0,0 +1,227 @@
+package sparse
+
+import (
+	"container/heap"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+// Ordering selects the fill-reducing permutation that Chol and LU apply
+// to the matrix before factorizing it.
+type Ordering int
+
+const (
+	// Natural keeps the matrix as it is.
+	Natural Ordering = iota
+	// MinDegree orders by AMD.
+	MinDegree
+)
+
+// order returns the permutation o for the n by n matrix a,
+// where perm[k] is the index of the row and col of a that
+// become row and col k.
+func order(o Ordering, a *CSC) []int {
+	switch o {
+	case Natural:
+		n, _ := a.Dims()
+		perm := make([]int, n)
+		for i := range perm {
+			perm[i] = i
+		}
+		return perm
+	case MinDegree:
+		return AMD(a)
+	}
+	panic(err("unknown ordering"))
+}
+
+// inverse returns the inverse of the permutation perm.
+func inverse(perm []int) []int {
+	inv := make([]int, len(perm))
+	for k, i := range perm {
+		inv[i] = k
+	}
+	return inv
+}
+
+// AMD returns an approximate minimum degree ordering of the square
+// matrix a, following Amestoy, Davis and Duff, "An approximate minimum
+// degree ordering algorithm", SIAM J. Matrix Anal. Appl. 17(4), 1996.
+// The ordering is computed on the pattern of a + a', so it suits
+// Cholesky factorization, and LU factorization of a matrix whose
+// pattern is close to symmetric.
+// perm[k] is the index of the row and col of a that is eliminated
+// k-th. This implementation leaves out the detection of
+// indistinguishable nodes (supervariables) of the full algorithm.
+func AMD(a *CSC) []int {
+	n, c := a.Dims()
+	if n != c {
+		panic(&dense.ShapeError{Op: "AMD", Err: dense.ErrSquare, Dims: [][2]int{{n, c}}})
+	}
+
+	// The quotient graph. A node is a variable until it is eliminated,
+	// when it becomes an element, the clique of the variables in
+	// elem[e]; an element that is adjacent to an eliminated node is
+	// absorbed into the element of the latter.
+	const (
+		variable = iota
+		element
+		absorbed
+	)
+	status := make([]int, n)
+	vars := make([][]int, n)  // adjacent variables, possibly stale
+	elems := make([][]int, n) // adjacent elements, possibly stale
+	elem := make([][]int, n)
+	for j := 0; j < n; j++ {
+		for k := a.indptr[j]; k < a.indptr[j+1]; k++ {
+			if i := a.ind[k]; i != j {
+				vars[i] = append(vars[i], j)
+				vars[j] = append(vars[j], i)
+			}
+		}
+	}
+
+	mark := make([]int, n)
+	stamp := 0
+	deg := make([]int, n)
+	h := &degree_heap{}
+	for i := range vars {
+		// Drop duplicates.
+		stamp++
+		mark[i] = stamp
+		vi := vars[i][:0]
+		for _, j := range vars[i] {
+			if mark[j] != stamp {
+				mark[j] = stamp
+				vi = append(vi, j)
+			}
+		}
+		vars[i] = vi
+		deg[i] = len(vi)
+		heap.Push(h, degree_node{deg[i], i})
+	}
+
+	w := make([]int, n)
+	for e := range w {
+		w[e] = -1
+	}
+	perm := make([]int, 0, n)
+	for len(perm) < n {
+		x := heap.Pop(h).(degree_node)
+		p := x.node
+		if status[p] != variable || x.deg != deg[p] {
+			continue // stale
+		}
+		perm = append(perm, p)
+
+		// The new element is the union of the variables adjacent to p,
+		// directly or through the elements that it absorbs.
+		stamp++
+		mark[p] = stamp
+		var lp []int
+		add := func(j int) {
+			if status[j] == variable && mark[j] != stamp {
+				mark[j] = stamp
+				lp = append(lp, j)
+			}
+		}
+		for _, j := range vars[p] {
+			add(j)
+		}
+		for _, e := range elems[p] {
+			if status[e] != element {
+				continue
+			}
+			for _, j := range elem[e] {
+				add(j)
+			}
+			status[e], elem[e] = absorbed, nil
+		}
+		status[p], elem[p], vars[p], elems[p] = element, lp, nil, nil
+
+		// Prune the adjacency of the variables in the new element:
+		// the variables in it are now reached through it.
+		for _, i := range lp {
+			vi := vars[i][:0]
+			for _, j := range vars[i] {
+				if status[j] == variable && mark[j] != stamp {
+					vi = append(vi, j)
+				}
+			}
+			vars[i] = vi
+			ei := elems[i][:0]
+			for _, e := range elems[i] {
+				if status[e] == element {
+					ei = append(ei, e)
+				}
+			}
+			elems[i] = append(ei, p)
+		}
+
+		// w[e] = |elem[e] \ lp| for the other elements adjacent to lp.
+		for _, i := range lp {
+			for _, e := range elems[i] {
+				if e == p {
+					continue
+				}
+				if w[e] < 0 {
+					w[e] = len(elem[e])
+				}
+				w[e]--
+			}
+		}
+		// The approximate external degree, an upper bound.
+		left := n - len(perm)
+		ext := len(lp) - 1
+		for _, i := range lp {
+			d := len(vars[i]) + ext
+			for _, e := range elems[i] {
+				if e != p {
+					d += w[e]
+				}
+			}
+			deg[i] = smaller(smaller(left-1, deg[i]+ext), d)
+			heap.Push(h, degree_node{deg[i], i})
+		}
+		for _, i := range lp {
+			for _, e := range elems[i] {
+				w[e] = -1
+			}
+		}
+	}
+	return perm
+}
+
+type degree_node struct {
+	deg, node int
+}
+
+// degree_heap is a min-heap of nodes by degree, then by index.
+type degree_heap []degree_node
+
+func (h degree_heap) Len() int { return len(h) }
+
+func (h degree_heap) Less(i, j int) bool {
+	if h[i].deg != h[j].deg {
+		return h[i].deg < h[j].deg
+	}
+	return h[i].node < h[j].node
+}
+
+func (h degree_heap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *degree_heap) Push(x interface{}) { *h = append(*h, x.(degree_node)) }
+
+func (h *degree_heap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func smaller(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,74 @@
+package sparse
+
+import (
+	"sort"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+// laplacian returns the 5-point Laplacian on an n by n grid,
+// built as a finite-element assembly would be.
+func laplacian(n int) *CSC {
+	coo := NewCOO(n*n, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			k := i*n + j
+			coo.Append(k, k, 4)
+			if i > 0 {
+				coo.Append(k, k-n, -1).Append(k-n, k, -1)
+			}
+			if j > 0 {
+				coo.Append(k, k-1, -1).Append(k-1, k, -1)
+			}
+		}
+	}
+	return coo.ToCSC()
+}
+
+// arrow returns an n by n matrix with a full first row and col,
+// and a diagonal.
+func arrow(n int) *CSC {
+	coo := NewCOO(n, n)
+	coo.Append(0, 0, float64(n))
+	for i := 1; i < n; i++ {
+		coo.Append(i, i, 2).Append(i, 0, 1).Append(0, i, 1)
+	}
+	return coo.ToCSC()
+}
+
+func is_perm(p []int, n int) bool {
+	q := append([]int(nil), p...)
+	sort.Ints(q)
+	for i, v := range q {
+		if v != i {
+			return false
+		}
+	}
+	return len(q) == n
+}
+
+func (s *S) TestAMD(c *check.C) {
+	for _, a := range []*CSC{laplacian(1), laplacian(10), arrow(20), CSCFrom(dense.NewDense(5, 5))} {
+		n, _ := a.Dims()
+		c.Check(is_perm(AMD(a), n), check.Equals, true)
+	}
+
+	// The hub of the arrow goes last but one at the earliest,
+	// and there is no fill.
+	p := AMD(arrow(20))
+	c.Check(p[18] == 0 || p[19] == 0, check.Equals, true)
+	ch, ok := Chol(arrow(20), MinDegree)
+	c.Check(ok, check.Equals, true)
+	c.Check(ch.L().NNZ(), check.Equals, 39)
+	ch, _ = Chol(arrow(20), Natural)
+	c.Check(ch.L().NNZ(), check.Equals, 20*21/2)
+
+	// Less fill than the banded natural order on a grid.
+	nat, _ := Chol(laplacian(20), Natural)
+	amd, _ := Chol(laplacian(20), MinDegree)
+	c.Check(amd.L().NNZ() < nat.L().NNZ(), check.Equals, true,
+		check.Commentf("AMD %d, natural %d", amd.L().NNZ(), nat.L().NNZ()))
+
+	c.Check(func() { AMD(CSCFrom(dense.NewDense(2, 3))) }, check.PanicMatches, ".*square.*")
+}
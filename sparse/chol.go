@@ -0,0 +1,273 @@
+package sparse
+
+import (
+	"math"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+// CholFactors contains the sparse Cholesky factorization
+//    P * a * P' = L * L'
+// of a symmetric positive definite matrix a, where P is the
+// fill-reducing permutation chosen by the Ordering.
+// It has the Solve, Det and LogDet methods of dense.CholFactors.
+type CholFactors struct {
+	perm []int // row k of P * a is row perm[k] of a
+	l    *CSC
+}
+
+// Chol computes the Cholesky factorization of the symmetric positive
+// definite matrix a, which is first permuted by the ordering o.
+// Only the lower triangle of a is read, as in dense.Chol.
+// The success flag is false if a is not square or not positive definite.
+//
+// This is the up-looking algorithm of CSparse (T. A. Davis, "Direct
+// Methods for Sparse Linear Systems", SIAM, 2006), which computes
+// L row by row, each row by a sparse triangular solve whose pattern
+// is found from the elimination tree.
+func Chol(a *CSC, o Ordering) (*CholFactors, bool) {
+	n, c := a.Dims()
+	if n != c {
+		return nil, false
+	}
+	perm := order(o, a)
+	pinv := inverse(perm)
+
+	// The upper triangle of the permuted matrix; its col k is
+	// row k of the lower triangle.
+	coo := NewCOO(n, n)
+	for j := 0; j < n; j++ {
+		for k := a.indptr[j]; k < a.indptr[j+1]; k++ {
+			if i := a.ind[k]; i >= j {
+				pi, pj := pinv[i], pinv[j]
+				if pi > pj {
+					pi, pj = pj, pi
+				}
+				coo.Append(pi, pj, a.data[k])
+			}
+		}
+	}
+	up := coo.ToCSC()
+
+	parent := etree(up)
+	stack := make([]int, n)
+	mark := make([]bool, n)
+
+	// Symbolic analysis: count the nonzeros in each col of L.
+	indptr := make([]int, n+1)
+	for k := 0; k < n; k++ {
+		indptr[k+1]++ // the diagonal
+		for _, i := range stack[ereach(up, k, parent, stack, mark):] {
+			indptr[i+1]++
+		}
+	}
+	for k := 0; k < n; k++ {
+		indptr[k+1] += indptr[k]
+	}
+	l := &CSC{compressed{n, n, indptr,
+		make([]int, indptr[n]), make([]float64, indptr[n])}}
+
+	// Numeric factorization.
+	next := append([]int(nil), indptr[:n]...) // next free slot in each col
+	x := make([]float64, n)
+	for k := 0; k < n; k++ {
+		top := ereach(up, k, parent, stack, mark)
+		for p := up.indptr[k]; p < up.indptr[k+1]; p++ {
+			x[up.ind[p]] = up.data[p]
+		}
+		d := x[k]
+		x[k] = 0
+		// Solve for row k of L, by the cols of L to the left.
+		for _, i := range stack[top:] {
+			lki := x[i] / l.data[l.indptr[i]]
+			x[i] = 0
+			for p := l.indptr[i] + 1; p < next[i]; p++ {
+				x[l.ind[p]] -= l.data[p] * lki
+			}
+			d -= lki * lki
+			l.ind[next[i]], l.data[next[i]] = k, lki
+			next[i]++
+		}
+		if d <= 0 {
+			return nil, false
+		}
+		l.ind[next[k]], l.data[next[k]] = k, math.Sqrt(d)
+		next[k]++
+	}
+
+	return &CholFactors{perm, l}, true
+}
+
+// etree returns the elimination tree of the symmetric matrix whose
+// upper triangle is a: parent[i] is the parent of node i, or -1 at
+// a root.
+func etree(a *CSC) []int {
+	n, _ := a.Dims()
+	parent := make([]int, n)
+	ancestor := make([]int, n)
+	for k := 0; k < n; k++ {
+		parent[k], ancestor[k] = -1, -1
+		for p := a.indptr[k]; p < a.indptr[k+1]; p++ {
+			// Walk up from i to the root of its subtree, compressing
+			// the path to k.
+			for i := a.ind[p]; i != -1 && i < k; {
+				next := ancestor[i]
+				ancestor[i] = k
+				if next == -1 {
+					parent[i] = k
+				}
+				i = next
+			}
+		}
+	}
+	return parent
+}
+
+// ereach finds the pattern of row k of L, for the symmetric matrix
+// whose upper triangle is a with elimination tree parent. The pattern
+// is left in stack[top:], in topological order, and top is returned.
+// mark must be all false, and is left so.
+func ereach(a *CSC, k int, parent, stack []int, mark []bool) int {
+	n := len(parent)
+	top := n
+	mark[k] = true
+	for p := a.indptr[k]; p < a.indptr[k+1]; p++ {
+		i := a.ind[p]
+		if i > k {
+			continue
+		}
+		// Climb the tree to a marked node, then push the path.
+		length := 0
+		for ; !mark[i]; i = parent[i] {
+			stack[length] = i
+			length++
+			mark[i] = true
+		}
+		for length > 0 {
+			top--
+			length--
+			stack[top] = stack[length]
+		}
+	}
+	for _, i := range stack[top:] {
+		mark[i] = false
+	}
+	mark[k] = false
+	return top
+}
+
+// L returns the Cholesky factor of the permuted matrix.
+// It is internal data of ch, which one is not expected to change.
+func (ch *CholFactors) L() *CSC {
+	return ch.l
+}
+
+// Perm returns the permutation P as a slice p, where row k of P * a
+// is row p[k] of a.
+func (ch *CholFactors) Perm() []int {
+	return append([]int(nil), ch.perm...)
+}
+
+// Solve returns a matrix x that solves a * x = b where a is the matrix
+// that produced ch by Chol(a).
+// The matrix b must have the same number of rows as a.
+// b is overwritten by the operation and returned containing the
+// solution.
+func (ch *CholFactors) Solve(b *dense.Dense) *dense.Dense {
+	return must_dense(ch.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (ch *CholFactors) TrySolve(b *dense.Dense) (*dense.Dense, error) {
+	n, _ := ch.l.Dims()
+	if b.Rows() != n {
+		return nil, shape_error("CholFactors.Solve", dense.ErrShapes, ch.l, b)
+	}
+	y := permute_rows(b, ch.perm, nil)
+	solve_lower(ch.l, false, y)
+	solve_upper(ch.l, true, y)
+	permute_rows(y, inverse(ch.perm), b)
+	return b, nil
+}
+
+// Det returns the determinant of the matrix a that produced ch by Chol(a).
+func (ch *CholFactors) Det() float64 {
+	v := 1.0
+	for j := 0; j < ch.l.major; j++ {
+		v *= ch.l.data[ch.l.indptr[j]]
+	}
+	return v * v
+}
+
+// LogDet returns the logarithm of the determinant of the matrix a
+// that produced ch by Chol(a).
+func (ch *CholFactors) LogDet() float64 {
+	v := 0.0
+	for j := 0; j < ch.l.major; j++ {
+		v += math.Log(ch.l.data[ch.l.indptr[j]])
+	}
+	return 2 * v
+}
+
+// permute_rows copies row perm[k] of b into row k of out, and returns
+// out. If out is nil, a new matrix is allocated and used.
+func permute_rows(b *dense.Dense, perm []int, out *dense.Dense) *dense.Dense {
+	if out == nil {
+		out = dense.NewDense(b.Rows(), b.Cols())
+	}
+	for k, i := range perm {
+		out.SetRow(k, b.RowView(i))
+	}
+	return out
+}
+
+// solve_lower solves l * x = b for the lower triangular l, with its
+// diagonal first in each col, overwriting b by x. If unit is true,
+// the diagonal is taken to be 1.
+func solve_lower(l *CSC, unit bool, b *dense.Dense) {
+	for j := 0; j < l.major; j++ {
+		from, to := l.indptr[j], l.indptr[j+1]
+		xj := b.RowView(j)
+		if !unit {
+			scale_row(xj, 1/l.data[from])
+		}
+		for p := from + 1; p < to; p++ {
+			axpy_row(b.RowView(l.ind[p]), -l.data[p], xj)
+		}
+	}
+}
+
+// solve_upper solves u * x = b for the upper triangular u, with its
+// diagonal last in each col, or, if trans is true, l' * x = b for the
+// lower triangular l, with its diagonal first, overwriting b by x.
+func solve_upper(m *CSC, trans bool, b *dense.Dense) {
+	for j := m.major - 1; j >= 0; j-- {
+		from, to := m.indptr[j], m.indptr[j+1]
+		xj := b.RowView(j)
+		if trans {
+			// Row j of l' is col j of l.
+			for p := from + 1; p < to; p++ {
+				axpy_row(xj, -m.data[p], b.RowView(m.ind[p]))
+			}
+			scale_row(xj, 1/m.data[from])
+			continue
+		}
+		scale_row(xj, 1/m.data[to-1])
+		for p := from; p < to-1; p++ {
+			axpy_row(b.RowView(m.ind[p]), -m.data[p], xj)
+		}
+	}
+}
+
+func scale_row(x []float64, v float64) {
+	for i := range x {
+		x[i] *= v
+	}
+}
+
+// axpy_row adds s * x to y.
+func axpy_row(y []float64, s float64, x []float64) {
+	for i, v := range x {
+		y[i] += s * v
+	}
+}
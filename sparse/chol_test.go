@@ -0,0 +1,63 @@
+package sparse
+
+import (
+	"errors"
+	"math"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+// permute returns p * a * q' for the permutations p and q,
+// where row i of the result is row p[i] of a and col j is col q[j].
+func permute(a dense.Matrix, p, q []int) *dense.Dense {
+	out := dense.NewDense(len(p), len(q))
+	for i, pi := range p {
+		for j, qj := range q {
+			out.Set(i, j, a.At(pi, qj))
+		}
+	}
+	return out
+}
+
+func (s *S) TestChol(c *check.C) {
+	for _, o := range []Ordering{Natural, MinDegree} {
+		a := laplacian(6)
+		ad := a.Full(nil)
+		ch, ok := Chol(a, o)
+		c.Assert(ok, check.Equals, true)
+		c.Check(ch.L().check(), check.IsNil)
+
+		l := ch.L().Full(nil)
+		p := ch.Perm()
+		c.Check(dense.Approx(dense.MultTrans(l, l, nil), permute(ad, p, p), 1e-12),
+			check.Equals, true)
+
+		b := rand_sparse(36, 3, 1)
+		x := ch.Solve(dense.Clone(b))
+		c.Check(dense.Approx(a.MultDense(x, nil), b, 1e-12), check.Equals, true)
+
+		dch, _ := dense.Chol(ad)
+		c.Check(math.Abs(ch.LogDet()-dch.LogDet()) < 1e-10, check.Equals, true)
+		c.Check(math.Abs(ch.Det()/dch.Det()-1) < 1e-10, check.Equals, true)
+	}
+
+	// Only the lower triangle is read.
+	a := dense.DenseView([]float64{
+		4, 100, 0,
+		1, 3, 100,
+		0, 1, 2,
+	}, 3, 3)
+	ch, ok := Chol(CSCFrom(a), MinDegree)
+	c.Assert(ok, check.Equals, true)
+	dch, _ := dense.Chol(a)
+	c.Check(math.Abs(ch.Det()-dch.Det()) < 1e-12, check.Equals, true)
+
+	_, ok = Chol(CSCFrom(dense.DenseView([]float64{1, 2, 2, 1}, 2, 2)), MinDegree)
+	c.Check(ok, check.Equals, false)
+	_, ok = Chol(CSCFrom(dense.NewDense(2, 3)), Natural)
+	c.Check(ok, check.Equals, false)
+
+	_, e := ch.TrySolve(dense.NewDense(2, 1))
+	c.Check(errors.Is(e, dense.ErrShapes), check.Equals, true)
+}
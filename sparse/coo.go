@@ -0,0 +1,95 @@
+package sparse
+
+// COO is a sparse matrix in coordinate format, that is, a list of
+// (row, col, value) triplets. It is meant for building a matrix,
+// e.g. in a finite-element assembly, before converting it by ToCSR
+// or ToCSC for computation. Triplets may come in any order, and
+// the values of duplicate entries are summed in the conversion.
+type COO struct {
+	rows, cols int
+	row, col   []int
+	data       []float64
+}
+
+// NewCOO creates an empty r by c matrix in coordinate format.
+func NewCOO(r, c int) *COO {
+	if r < 0 || c < 0 {
+		panic(ErrIndexOutOfRange)
+	}
+	return &COO{rows: r, cols: c}
+}
+
+func (m *COO) Dims() (r, c int) { return m.rows, m.cols }
+
+// NNZ returns the number of triplets, duplicates included.
+func (m *COO) NNZ() int { return len(m.data) }
+
+// Append adds v to element (r, c), and returns m.
+func (m *COO) Append(r, c int, v float64) *COO {
+	if r < 0 || r >= m.rows || c < 0 || c >= m.cols {
+		panic(ErrIndexOutOfRange)
+	}
+	m.row = append(m.row, r)
+	m.col = append(m.col, c)
+	m.data = append(m.data, v)
+	return m
+}
+
+// ToCSR returns m in compressed sparse row format.
+func (m *COO) ToCSR() *CSR {
+	return &CSR{compress(m.rows, m.cols, m.row, m.col, m.data)}
+}
+
+// ToCSC returns m in compressed sparse col format.
+func (m *COO) ToCSC() *CSC {
+	return &CSC{compress(m.cols, m.rows, m.col, m.row, m.data)}
+}
+
+// compress builds compressed storage from triplets, summing duplicates.
+// It counting-sorts the triplets by minor index, then stably by major
+// index, which leaves the minor indices of each major vector increasing.
+func compress(major, minor int, majors, minors []int, data []float64) compressed {
+	nnz := len(data)
+	bucket := func(n int, key []int, order []int) []int {
+		start := make([]int, n+1)
+		for _, k := range key {
+			start[k+1]++
+		}
+		for i := 0; i < n; i++ {
+			start[i+1] += start[i]
+		}
+		out := make([]int, nnz)
+		for _, p := range order {
+			out[start[key[p]]] = p
+			start[key[p]]++
+		}
+		return out
+	}
+	order := make([]int, nnz)
+	for p := range order {
+		order[p] = p
+	}
+	order = bucket(major, majors, bucket(minor, minors, order))
+
+	c := compressed{
+		major:  major,
+		minor:  minor,
+		indptr: make([]int, major+1),
+		ind:    make([]int, 0, nnz),
+		data:   make([]float64, 0, nnz),
+	}
+	for k, p := range order {
+		i, j := majors[p], minors[p]
+		if k > 0 && majors[order[k-1]] == i && minors[order[k-1]] == j {
+			c.data[len(c.data)-1] += data[p]
+			continue
+		}
+		c.ind = append(c.ind, j)
+		c.data = append(c.data, data[p])
+		c.indptr[i+1]++
+	}
+	for i := 0; i < major; i++ {
+		c.indptr[i+1] += c.indptr[i]
+	}
+	return c
+}
@@ -0,0 +1,36 @@
+package sparse
+
+import (
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestCOO(c *check.C) {
+	m := NewCOO(3, 4)
+	m.Append(2, 3, 1).Append(0, 1, 2).Append(2, 0, 3)
+	m.Append(0, 1, 4).Append(1, 2, 5).Append(2, 0, -3)
+	c.Check(m.NNZ(), check.Equals, 6)
+
+	want := dense.DenseView([]float64{
+		0, 6, 0, 0,
+		0, 0, 5, 0,
+		0, 0, 0, 1,
+	}, 3, 4)
+
+	// Duplicates are summed; (2, 0) stays as an explicit zero.
+	csr := m.ToCSR()
+	c.Check(csr.check(), check.IsNil)
+	c.Check(csr.NNZ(), check.Equals, 4)
+	c.Check(dense.Equal(csr, want), check.Equals, true)
+
+	csc := m.ToCSC()
+	c.Check(csc.check(), check.IsNil)
+	c.Check(csc.NNZ(), check.Equals, 4)
+	c.Check(dense.Equal(csc, want), check.Equals, true)
+
+	c.Check(func() { m.Append(3, 0, 1) }, check.Panics, ErrIndexOutOfRange)
+
+	empty := NewCOO(2, 0).ToCSR()
+	r, cols := empty.Dims()
+	c.Check([]int{r, cols, empty.NNZ()}, check.DeepEquals, []int{2, 0, 0})
+}
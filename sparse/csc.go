@@ -0,0 +1,129 @@
+package sparse
+
+import (
+	"github.com/zpz/matrix.go/dense"
+)
+
+// CSC is a sparse matrix in compressed sparse col format.
+// The nonzeros of col j are at rows ind[indptr[j]:indptr[j+1]],
+// in increasing order, with values data[indptr[j]:indptr[j+1]].
+type CSC struct {
+	compressed
+}
+
+// NewCSC creates an r by c matrix in compressed sparse col format
+// from its constituent slices, which are used without copying.
+// NewCSC panics with ErrStructure if they are inconsistent.
+func NewCSC(r, c int, indptr, ind []int, data []float64) *CSC {
+	m := &CSC{compressed{c, r, indptr, ind, data}}
+	if e := m.check(); e != nil {
+		panic(e)
+	}
+	return m
+}
+
+// CSCFrom returns the nonzeros of m in compressed sparse col format.
+func CSCFrom(m dense.Matrix) *CSC {
+	return &CSC{from_dense(m, true)}
+}
+
+func (m *CSC) Dims() (r, c int) { return m.minor, m.major }
+
+// NNZ returns the number of stored elements.
+func (m *CSC) NNZ() int { return m.nnz() }
+
+func (m *CSC) At(r, c int) float64 { return m.at(c, r) }
+
+// ColView returns col c as a view.
+func (m *CSC) ColView(c int) *Float64Sparse { return m.vec(c) }
+
+// GetCol copies col c, zeros included, into col.
+// If col is nil, a new slice is allocated.
+func (m *CSC) GetCol(c int, col []float64) []float64 {
+	return m.vec(c).CopyToSlice(col)
+}
+
+// T returns the transpose of m, which shares storage with m.
+func (m *CSC) T() *CSR { return &CSR{m.compressed} }
+
+// ToCSR returns m in compressed sparse row format.
+func (m *CSC) ToCSR() *CSR { return &CSR{m.transpose()} }
+
+// Full copies m into out, and returns out.
+// If out is nil, a new matrix is allocated and used.
+func (m *CSC) Full(out *dense.Dense) *dense.Dense {
+	out, e := use_out("CSC.Full", out, m.minor, m.major)
+	if e != nil {
+		panic(e)
+	}
+	m.full(out, true)
+	return out
+}
+
+// Scale multiplies every element of m by v in-place,
+// and returns m.
+func (m *CSC) Scale(v float64) *CSC {
+	for k := range m.data {
+		m.data[k] *= v
+	}
+	return m
+}
+
+// Add adds b to m, and returns m. Elements that cancel out
+// are dropped from m.
+func (m *CSC) Add(b *CSC) *CSC {
+	return m.AddScaled(b, 1)
+}
+
+// AddScaled adds s * b to m, and returns m.
+func (m *CSC) AddScaled(b *CSC, s float64) *CSC {
+	if _, e := m.TryAddScaled(b, s); e != nil {
+		panic(e)
+	}
+	return m
+}
+
+// TryAddScaled is AddScaled returning an error instead of panicking.
+func (m *CSC) TryAddScaled(b *CSC, s float64) (*CSC, error) {
+	if m.major != b.major || m.minor != b.minor {
+		return nil, shape_error("CSC.AddScaled", dense.ErrShapes, m, b)
+	}
+	m.compressed = m.add_scaled(&b.compressed, s)
+	return m, nil
+}
+
+// MultVec returns the product of m and the vector x in out.
+// If out is nil, a new slice is allocated;
+// otherwise it must have length equal to the number of rows of m,
+// and must not share storage with x.
+func (m *CSC) MultVec(x, out []float64) []float64 {
+	return must_slice(m.TryMultVec(x, out))
+}
+
+// TryMultVec is MultVec returning an error instead of panicking.
+func (m *CSC) TryMultVec(x, out []float64) ([]float64, error) {
+	out, e := use_vec("CSC.MultVec", m, x, out, m.minor, m.major)
+	if e != nil {
+		return nil, e
+	}
+	m.mult_vec(true, x, out)
+	return out, nil
+}
+
+// MultDense returns the product of m and b in out.
+// If out is nil, a new matrix is allocated and used;
+// otherwise out must have the correct shape, and must not share
+// storage with b, unless it is b itself.
+func (m *CSC) MultDense(b, out *dense.Dense) *dense.Dense {
+	return must_dense(m.TryMultDense(b, out))
+}
+
+// TryMultDense is MultDense returning an error instead of panicking.
+func (m *CSC) TryMultDense(b, out *dense.Dense) (*dense.Dense, error) {
+	b, out, e := use_mult_out("CSC.MultDense", m, b, out, m.minor, m.major)
+	if e != nil {
+		return nil, e
+	}
+	m.mult_dense(true, b, out)
+	return out, nil
+}
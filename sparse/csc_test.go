@@ -0,0 +1,54 @@
+package sparse
+
+import (
+	"errors"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestCSC(c *check.C) {
+	m := NewCSC(3, 4, []int{0, 1, 2, 3, 4}, []int{2, 0, 2, 0}, []float64{3, 1, 4, 2})
+	want := dense.DenseView([]float64{
+		0, 1, 0, 2,
+		0, 0, 0, 0,
+		3, 0, 4, 0,
+	}, 3, 4)
+	c.Check(dense.Equal(m, want), check.Equals, true)
+	c.Check(dense.Equal(m.Full(nil), want), check.Equals, true)
+	c.Check(dense.Equal(CSCFrom(want), m), check.Equals, true)
+	c.Check(m.GetCol(2, nil), check.DeepEquals, []float64{0, 0, 4})
+	c.Check(m.ColView(3).Indices(), check.DeepEquals, []int{0})
+
+	c.Check(dense.Equal(m.T(), want.TView()), check.Equals, true)
+	c.Check(dense.Equal(m.ToCSR(), want), check.Equals, true)
+	c.Check(func() { m.At(0, 4) }, check.Panics, ErrIndexOutOfRange)
+}
+
+func (s *S) TestCSCArith(c *check.C) {
+	a := rand_sparse(20, 30, 0.2)
+	b := rand_sparse(20, 30, 0.2)
+	m := CSCFrom(a)
+
+	m.Add(CSCFrom(b)).Scale(0.5)
+	c.Check(m.check(), check.IsNil)
+	c.Check(dense.Approx(m, dense.Scale(dense.Add(a, b, nil), 0.5, nil), 1e-14),
+		check.Equals, true)
+
+	_, e := m.TryAddScaled(CSCFrom(dense.NewDense(20, 20)), 1)
+	c.Check(errors.Is(e, dense.ErrShapes), check.Equals, true)
+}
+
+func (s *S) TestCSCMult(c *check.C) {
+	a := rand_sparse(20, 30, 0.2)
+	b := rand_sparse(30, 5, 1)
+	m := CSCFrom(a)
+
+	want := dense.Mult(a, b, nil)
+	c.Check(dense.Approx(m.MultDense(b, nil), want, 1e-12), check.Equals, true)
+
+	x := b.GetCol(1, nil)
+	got := m.MultVec(x, make([]float64, 20))
+	c.Check(dense.Approx(dense.DenseView(got, 20, 1),
+		want.SubmatrixView(0, 1, 20, 1), 1e-12), check.Equals, true)
+}
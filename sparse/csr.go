@@ -0,0 +1,129 @@
+package sparse
+
+import (
+	"github.com/zpz/matrix.go/dense"
+)
+
+// CSR is a sparse matrix in compressed sparse row format.
+// The nonzeros of row i are at cols ind[indptr[i]:indptr[i+1]],
+// in increasing order, with values data[indptr[i]:indptr[i+1]].
+type CSR struct {
+	compressed
+}
+
+// NewCSR creates an r by c matrix in compressed sparse row format
+// from its constituent slices, which are used without copying.
+// NewCSR panics with ErrStructure if they are inconsistent.
+func NewCSR(r, c int, indptr, ind []int, data []float64) *CSR {
+	m := &CSR{compressed{r, c, indptr, ind, data}}
+	if e := m.check(); e != nil {
+		panic(e)
+	}
+	return m
+}
+
+// CSRFrom returns the nonzeros of m in compressed sparse row format.
+func CSRFrom(m dense.Matrix) *CSR {
+	return &CSR{from_dense(m, false)}
+}
+
+func (m *CSR) Dims() (r, c int) { return m.major, m.minor }
+
+// NNZ returns the number of stored elements.
+func (m *CSR) NNZ() int { return m.nnz() }
+
+func (m *CSR) At(r, c int) float64 { return m.at(r, c) }
+
+// RowView returns row r as a view.
+func (m *CSR) RowView(r int) *Float64Sparse { return m.vec(r) }
+
+// GetRow copies row r, zeros included, into row.
+// If row is nil, a new slice is allocated.
+func (m *CSR) GetRow(r int, row []float64) []float64 {
+	return m.vec(r).CopyToSlice(row)
+}
+
+// T returns the transpose of m, which shares storage with m.
+func (m *CSR) T() *CSC { return &CSC{m.compressed} }
+
+// ToCSC returns m in compressed sparse col format.
+func (m *CSR) ToCSC() *CSC { return &CSC{m.transpose()} }
+
+// Full copies m into out, and returns out.
+// If out is nil, a new matrix is allocated and used.
+func (m *CSR) Full(out *dense.Dense) *dense.Dense {
+	out, e := use_out("CSR.Full", out, m.major, m.minor)
+	if e != nil {
+		panic(e)
+	}
+	m.full(out, false)
+	return out
+}
+
+// Scale multiplies every element of m by v in-place,
+// and returns m.
+func (m *CSR) Scale(v float64) *CSR {
+	for k := range m.data {
+		m.data[k] *= v
+	}
+	return m
+}
+
+// Add adds b to m, and returns m. Elements that cancel out
+// are dropped from m.
+func (m *CSR) Add(b *CSR) *CSR {
+	return m.AddScaled(b, 1)
+}
+
+// AddScaled adds s * b to m, and returns m.
+func (m *CSR) AddScaled(b *CSR, s float64) *CSR {
+	if _, e := m.TryAddScaled(b, s); e != nil {
+		panic(e)
+	}
+	return m
+}
+
+// TryAddScaled is AddScaled returning an error instead of panicking.
+func (m *CSR) TryAddScaled(b *CSR, s float64) (*CSR, error) {
+	if m.major != b.major || m.minor != b.minor {
+		return nil, shape_error("CSR.AddScaled", dense.ErrShapes, m, b)
+	}
+	m.compressed = m.add_scaled(&b.compressed, s)
+	return m, nil
+}
+
+// MultVec returns the product of m and the vector x in out.
+// If out is nil, a new slice is allocated;
+// otherwise it must have length equal to the number of rows of m,
+// and must not share storage with x.
+func (m *CSR) MultVec(x, out []float64) []float64 {
+	return must_slice(m.TryMultVec(x, out))
+}
+
+// TryMultVec is MultVec returning an error instead of panicking.
+func (m *CSR) TryMultVec(x, out []float64) ([]float64, error) {
+	out, e := use_vec("CSR.MultVec", m, x, out, m.major, m.minor)
+	if e != nil {
+		return nil, e
+	}
+	m.mult_vec(false, x, out)
+	return out, nil
+}
+
+// MultDense returns the product of m and b in out.
+// If out is nil, a new matrix is allocated and used;
+// otherwise out must have the correct shape, and must not share
+// storage with b, unless it is b itself.
+func (m *CSR) MultDense(b, out *dense.Dense) *dense.Dense {
+	return must_dense(m.TryMultDense(b, out))
+}
+
+// TryMultDense is MultDense returning an error instead of panicking.
+func (m *CSR) TryMultDense(b, out *dense.Dense) (*dense.Dense, error) {
+	b, out, e := use_mult_out("CSR.MultDense", m, b, out, m.major, m.minor)
+	if e != nil {
+		return nil, e
+	}
+	m.mult_dense(false, b, out)
+	return out, nil
+}
@@ -0,0 +1,98 @@
+package sparse
+
+import (
+	"errors"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestCSR(c *check.C) {
+	m := NewCSR(3, 4, []int{0, 2, 2, 4}, []int{1, 3, 0, 2}, []float64{1, 2, 3, 4})
+	want := dense.DenseView([]float64{
+		0, 1, 0, 2,
+		0, 0, 0, 0,
+		3, 0, 4, 0,
+	}, 3, 4)
+	c.Check(dense.Equal(m, want), check.Equals, true)
+	c.Check(dense.Equal(m.Full(nil), want), check.Equals, true)
+	c.Check(dense.Equal(CSRFrom(want), m), check.Equals, true)
+
+	row := m.RowView(2)
+	c.Check(row.Len(), check.Equals, 4)
+	c.Check(row.NNZ(), check.Equals, 2)
+	c.Check(row.Indices(), check.DeepEquals, []int{0, 2})
+	c.Check(row.Get(2), check.Equals, 4.0)
+	c.Check(row.Get(1), check.Equals, 0.0)
+	c.Check(row.Sum(), check.Equals, 7.0)
+	c.Check(row.Dot([]float64{1, 2, 3, 4}), check.Equals, 15.0)
+	c.Check(m.GetRow(0, nil), check.DeepEquals, []float64{0, 1, 0, 2})
+	c.Check(m.GetRow(1, nil), check.DeepEquals, []float64{0, 0, 0, 0})
+
+	c.Check(func() { m.At(3, 0) }, check.Panics, ErrIndexOutOfRange)
+	c.Check(func() { NewCSR(2, 2, []int{0, 1, 1}, []int{2}, []float64{1}) },
+		check.Panics, ErrStructure)
+
+	// The transpose shares storage.
+	t := m.T()
+	c.Check(dense.Equal(t, want.TView()), check.Equals, true)
+	t.ColView(0).Values()[0] = 10
+	c.Check(m.At(0, 1), check.Equals, 10.0)
+
+	csc := m.ToCSC()
+	c.Check(csc.check(), check.IsNil)
+	c.Check(dense.Equal(csc, m), check.Equals, true)
+	c.Check(dense.Equal(csc.ToCSR(), m), check.Equals, true)
+}
+
+func (s *S) TestCSRArith(c *check.C) {
+	a := rand_sparse(20, 30, 0.2)
+	b := rand_sparse(20, 30, 0.2)
+	m := CSRFrom(a)
+
+	m.AddScaled(CSRFrom(b), -2)
+	c.Check(m.check(), check.IsNil)
+	c.Check(dense.Approx(m, dense.AddScaled(a, b, -2, nil), 1e-14), check.Equals, true)
+
+	m.Scale(3)
+	c.Check(dense.Approx(m, dense.Scale(dense.AddScaled(a, b, -2, nil), 3, nil), 1e-14),
+		check.Equals, true)
+
+	// Cancelled elements are dropped.
+	m = CSRFrom(a).Add(CSRFrom(dense.Scale(a, -1, nil)))
+	c.Check(m.NNZ(), check.Equals, 0)
+
+	_, e := CSRFrom(a).TryAddScaled(CSRFrom(dense.NewDense(30, 20)), 1)
+	c.Check(errors.Is(e, dense.ErrShapes), check.Equals, true)
+}
+
+func (s *S) TestCSRMult(c *check.C) {
+	a := rand_sparse(20, 30, 0.2)
+	b := rand_sparse(30, 5, 1)
+	m := CSRFrom(a)
+
+	want := dense.Mult(a, b, nil)
+	c.Check(dense.Approx(m.MultDense(b, nil), want, 1e-12), check.Equals, true)
+	out := dense.NewDense(20, 5).Fill(1)
+	m.MultDense(b, out)
+	c.Check(dense.Approx(out, want, 1e-12), check.Equals, true)
+
+	x := b.GetCol(0, nil)
+	c.Check(dense.Approx(dense.DenseView(m.MultVec(x, nil), 20, 1),
+		want.SubmatrixView(0, 0, 20, 1), 1e-12), check.Equals, true)
+
+	// The product written over b.
+	sq := CSRFrom(rand_sparse(30, 30, 0.2))
+	want = dense.Mult(sq, b, nil)
+	sq.MultDense(b, b)
+	c.Check(dense.Approx(b, want, 1e-12), check.Equals, true)
+
+	_, e := m.TryMultDense(dense.NewDense(20, 5), nil)
+	c.Check(errors.Is(e, dense.ErrShapes), check.Equals, true)
+	_, e = m.TryMultDense(b, dense.NewDense(5, 5))
+	c.Check(errors.Is(e, dense.ErrOutShape), check.Equals, true)
+	_, e = m.TryMultVec(make([]float64, 20), nil)
+	c.Check(errors.Is(e, dense.ErrInLength), check.Equals, true)
+	_, e = m.TryMultVec(x, make([]float64, 30))
+	c.Check(e, check.Equals, dense.ErrOutLength)
+}
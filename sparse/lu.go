@@ -0,0 +1,278 @@
+package sparse
+
+import (
+	"math"
+	"sort"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+// LUFactors contains the sparse LU factorization
+//    P * a * Q = L * U
+// of a square matrix a, where L is unit lower triangular, U is upper
+// triangular, Q is the fill-reducing col permutation chosen by the
+// Ordering and P the row permutation of partial pivoting.
+// It has the IsSingular, Solve and Det methods of dense.LUFactors.
+type LUFactors struct {
+	pinv []int // row i of a is row pinv[i] of P * a
+	q    []int // col k of a * Q is col q[k] of a
+	l, u *CSC
+}
+
+// LU computes the LU factorization of the square matrix a, whose cols
+// are first permuted by the ordering o, with partial pivoting by rows.
+// As dense.LU, it does not fail on a singular matrix; check IsSingular
+// before solving.
+//
+// This is the left-looking algorithm of Gilbert and Peierls, as in
+// CSparse (T. A. Davis, "Direct Methods for Sparse Linear Systems",
+// SIAM, 2006): each col of L and U comes from a sparse triangular solve
+// with the cols of L found so far, whose pattern is found by a
+// depth-first search in the graph of L.
+func LU(a *CSC, o Ordering) *LUFactors {
+	n, c := a.Dims()
+	if n != c {
+		panic(&dense.ShapeError{Op: "LU", Err: dense.ErrSquare, Dims: [][2]int{{n, c}}})
+	}
+	q := order(o, a)
+
+	pinv := make([]int, n)
+	for i := range pinv {
+		pinv[i] = -1
+	}
+	l := &CSC{compressed{n, n, make([]int, n+1), nil, nil}}
+	u := &CSC{compressed{n, n, make([]int, n+1), nil, nil}}
+	x := make([]float64, n)
+	stack := make([]int, n)
+	work := make([]int, 2*n)
+	mark := make([]bool, n)
+	unpivoted := 0 // the smallest row that may not have been pivoted
+
+	for k := 0; k < n; k++ {
+		l.indptr[k], u.indptr[k] = len(l.ind), len(u.ind)
+
+		// x = L \ a(:, q[k]), with the rows of L in terms of a.
+		top := lower_reach(l, a, q[k], stack, work, mark, pinv)
+		pattern := stack[top:]
+		for p := a.indptr[q[k]]; p < a.indptr[q[k]+1]; p++ {
+			x[a.ind[p]] = a.data[p]
+		}
+		for _, j := range pattern {
+			jj := pinv[j]
+			if jj < 0 {
+				continue
+			}
+			for p := l.indptr[jj] + 1; p < l.indptr[jj+1]; p++ {
+				x[l.ind[p]] -= l.data[p] * x[j]
+			}
+		}
+
+		// The elements in pivoted rows go to U; the largest of the
+		// others is the pivot.
+		ipiv, big := -1, -1.0
+		for _, i := range pattern {
+			if pinv[i] < 0 {
+				if v := math.Abs(x[i]); v > big {
+					ipiv, big = i, v
+				}
+			} else {
+				u.ind = append(u.ind, pinv[i])
+				u.data = append(u.data, x[i])
+			}
+		}
+		if ipiv < 0 {
+			// A structurally singular col: pivot on any row left.
+			for pinv[unpivoted] >= 0 {
+				unpivoted++
+			}
+			ipiv = unpivoted
+		}
+		pivot := x[ipiv]
+		u.ind = append(u.ind, k)
+		u.data = append(u.data, pivot)
+		pinv[ipiv] = k
+		l.ind = append(l.ind, ipiv)
+		l.data = append(l.data, 1)
+		for _, i := range pattern {
+			if pinv[i] < 0 {
+				v := x[i]
+				if pivot != 0 {
+					// As dense.LU, leave the multipliers of a zero pivot.
+					v /= pivot
+				}
+				l.ind = append(l.ind, i)
+				l.data = append(l.data, v)
+			}
+			x[i] = 0
+		}
+		x[ipiv] = 0
+	}
+	l.indptr[n], u.indptr[n] = len(l.ind), len(u.ind)
+
+	// Rows of L in terms of P * a; sort the cols of both factors.
+	for p, i := range l.ind {
+		l.ind[p] = pinv[i]
+	}
+	l.sort()
+	u.sort()
+
+	return &LUFactors{pinv, q, l, u}
+}
+
+// lower_reach finds the pattern of the solution of l * x = a(:, k),
+// where the rows of l are in terms of a, and col pinv[i] of l is
+// the col for row i; a row with pinv[i] < 0 has no col yet. The
+// pattern is left in stack[top:], in topological order, and top is
+// returned. mark must be all false, and is left so; work, of length
+// 2n, is scratch.
+func lower_reach(l, a *CSC, k int, stack, work []int, mark []bool, pinv []int) int {
+	n := len(pinv)
+	top := n
+	for p := a.indptr[k]; p < a.indptr[k+1]; p++ {
+		if i := a.ind[p]; !mark[i] {
+			top = dfs(l, i, top, stack, work, mark, pinv)
+		}
+	}
+	for _, i := range stack[top:] {
+		mark[i] = false
+	}
+	return top
+}
+
+// dfs does a depth-first search of the graph of l from node j, and
+// pushes the nodes it finishes onto stack[:top]. It returns the new
+// top. work[:n] is the recursion stack, and work[n:] keeps track of
+// the position within the col of each node on it.
+func dfs(l *CSC, j, top int, stack, work []int, mark []bool, pinv []int) int {
+	n := len(pinv)
+	head := 0
+	work[0] = j
+	for head >= 0 {
+		j = work[head]
+		jj := pinv[j]
+		if !mark[j] {
+			mark[j] = true
+			if jj < 0 {
+				work[n+head] = 0
+			} else {
+				work[n+head] = l.indptr[jj] + 1 // skip the unit diagonal
+			}
+		}
+		done := true
+		if jj >= 0 {
+			end := l.indptr[jj+1]
+			for p := work[n+head]; p < end; p++ {
+				if i := l.ind[p]; !mark[i] {
+					work[n+head] = p + 1
+					head++
+					work[head] = i
+					done = false
+					break
+				}
+			}
+		}
+		if done {
+			head--
+			top--
+			stack[top] = j
+		}
+	}
+	return top
+}
+
+// sort sorts the elements of each col of m by row.
+func (m *compressed) sort() {
+	for j := 0; j < m.major; j++ {
+		from, to := m.indptr[j], m.indptr[j+1]
+		sort.Sort(by_index{m.ind[from:to], m.data[from:to]})
+	}
+}
+
+type by_index struct {
+	ind  []int
+	data []float64
+}
+
+func (s by_index) Len() int           { return len(s.ind) }
+func (s by_index) Less(i, j int) bool { return s.ind[i] < s.ind[j] }
+func (s by_index) Swap(i, j int) {
+	s.ind[i], s.ind[j] = s.ind[j], s.ind[i]
+	s.data[i], s.data[j] = s.data[j], s.data[i]
+}
+
+// IsSingular returns whether U and hence a is singular.
+func (f *LUFactors) IsSingular() bool {
+	for j := 0; j < f.u.major; j++ {
+		if f.u.data[f.u.indptr[j+1]-1] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// L returns the unit lower triangular factor.
+// It is internal data of f, which one is not expected to change.
+func (f *LUFactors) L() *CSC { return f.l }
+
+// U returns the upper triangular factor.
+// It is internal data of f, which one is not expected to change.
+func (f *LUFactors) U() *CSC { return f.u }
+
+// RowPerm returns the row permutation P as a slice p, where
+// row k of P * a is row p[k] of a.
+func (f *LUFactors) RowPerm() []int { return inverse(f.pinv) }
+
+// ColPerm returns the col permutation Q as a slice q, where
+// col k of a * Q is col q[k] of a.
+func (f *LUFactors) ColPerm() []int { return append([]int(nil), f.q...) }
+
+// Det returns the determinant of the matrix a that produced f.
+func (f *LUFactors) Det() float64 {
+	d := float64(perm_sign(f.pinv) * perm_sign(f.q))
+	for j := 0; j < f.u.major; j++ {
+		d *= f.u.data[f.u.indptr[j+1]-1]
+	}
+	return d
+}
+
+// perm_sign returns the sign of the permutation perm.
+func perm_sign(perm []int) int {
+	sign := 1
+	seen := make([]bool, len(perm))
+	for i := range perm {
+		if seen[i] {
+			continue
+		}
+		// A cycle of length c has sign (-1)^(c-1).
+		for j := perm[i]; j != i; j = perm[j] {
+			seen[j] = true
+			sign = -sign
+		}
+		seen[i] = true
+	}
+	return sign
+}
+
+// Solve returns a matrix x that solves a * x = b where a is the matrix
+// that produced f. The matrix b must have the same number of rows as a.
+// b is overwritten by the operation and returned containing the
+// solution. Solve panics with dense.ErrSingular if a is singular.
+func (f *LUFactors) Solve(b *dense.Dense) *dense.Dense {
+	return must_dense(f.TrySolve(b))
+}
+
+// TrySolve is Solve returning an error instead of panicking.
+func (f *LUFactors) TrySolve(b *dense.Dense) (*dense.Dense, error) {
+	n, _ := f.l.Dims()
+	if b.Rows() != n {
+		return nil, shape_error("LUFactors.Solve", dense.ErrShapes, f.l, b)
+	}
+	if f.IsSingular() {
+		return nil, dense.ErrSingular
+	}
+	y := permute_rows(b, inverse(f.pinv), nil)
+	solve_lower(f.l, true, y)
+	solve_upper(f.u, false, y)
+	permute_rows(y, inverse(f.q), b)
+	return b, nil
+}
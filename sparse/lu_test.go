@@ -0,0 +1,58 @@
+package sparse
+
+import (
+	"math"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func (s *S) TestLU(c *check.C) {
+	for _, o := range []Ordering{Natural, MinDegree} {
+		ad := rand_sparse(30, 30, 0.1)
+		for i := 0; i < 30; i++ {
+			ad.Set(i, i, ad.Get(i, i)+0.1)
+		}
+		a := CSCFrom(ad)
+		f := LU(a, o)
+		c.Check(f.L().check(), check.IsNil)
+		c.Check(f.U().check(), check.IsNil)
+		c.Check(f.IsSingular(), check.Equals, false)
+
+		l, u := f.L().Full(nil), f.U().Full(nil)
+		for i := 0; i < 30; i++ {
+			c.Check(l.Get(i, i), check.Equals, 1.0)
+			for j := 0; j < i; j++ {
+				c.Check(u.Get(i, j), check.Equals, 0.0)
+				c.Check(l.Get(j, i), check.Equals, 0.0)
+				// Partial pivoting keeps the multipliers small.
+				c.Check(math.Abs(l.Get(i, j)) <= 1, check.Equals, true)
+			}
+		}
+		c.Check(dense.Approx(dense.Mult(l, u, nil), permute(ad, f.RowPerm(), f.ColPerm()), 1e-12),
+			check.Equals, true)
+
+		b := rand_sparse(30, 2, 1)
+		x := f.Solve(dense.Clone(b))
+		c.Check(dense.Approx(a.MultDense(x, nil), b, 1e-10), check.Equals, true)
+
+		det := dense.LU(dense.Clone(ad)).Det()
+		c.Check(math.Abs(f.Det()/det-1) < 1e-10, check.Equals, true)
+	}
+
+	// A zero pivot, and a structurally zero col.
+	for _, ad := range []*dense.Dense{
+		dense.DenseView([]float64{1, 2, 3, 2, 4, 6, 1, 1, 1}, 3, 3),
+		dense.DenseView([]float64{1, 0, 3, 2, 0, 6, 1, 0, 1}, 3, 3),
+	} {
+		f := LU(CSCFrom(ad), MinDegree)
+		c.Check(f.IsSingular(), check.Equals, true)
+		c.Check(f.Det(), check.Equals, 0.0)
+		_, e := f.TrySolve(dense.NewDense(3, 1))
+		c.Check(e, check.Equals, dense.ErrSingular)
+	}
+
+	c.Check(perm_sign([]int{0, 1, 2}), check.Equals, 1)
+	c.Check(perm_sign([]int{1, 0, 2}), check.Equals, -1)
+	c.Check(perm_sign([]int{1, 2, 0}), check.Equals, 1)
+}
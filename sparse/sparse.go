@@ -0,0 +1,298 @@
+// Package sparse provides sparse matrices, which store only their
+// nonzero elements.
+//
+// A COO collects the nonzeros of a matrix as (row, col, value) triplets,
+// in any order, and is converted into a CSR (compressed sparse row) or
+// CSC (compressed sparse col) matrix for computation. CSR is efficient
+// for row access and for products with a vector or a dense matrix;
+// CSC for col access. Transposing one gives the other without copying.
+//
+// Chol and LU factorize a CSC matrix after reordering it to reduce
+// fill, and solve systems with it like their counterparts in dense.
+//
+// CSR and CSC implement dense.Matrix, hence they can be passed to the
+// functions of package dense that accept one, at the cost of a dense copy.
+package sparse
+
+import (
+	"sort"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+type err string
+
+func (e err) Error() string {
+	return "sparse: " + string(e)
+}
+
+const (
+	ErrIndexOutOfRange = err("index out of range")
+	ErrStructure       = err("malformed compressed structure")
+)
+
+// compressed is the storage shared by CSR and CSC.
+// Along the major dimension (the rows of a CSR, the cols of a CSC),
+// vector i has its nonzeros at minor indices ind[indptr[i]:indptr[i+1]],
+// which are increasing, with values data[indptr[i]:indptr[i+1]].
+type compressed struct {
+	major, minor int
+	indptr       []int
+	ind          []int
+	data         []float64
+}
+
+// check reports whether c is well-formed.
+func (c *compressed) check() error {
+	if c.major < 0 || c.minor < 0 || len(c.indptr) != c.major+1 ||
+		c.indptr[0] != 0 || c.indptr[c.major] != len(c.ind) ||
+		len(c.ind) != len(c.data) {
+		return ErrStructure
+	}
+	for i := 0; i < c.major; i++ {
+		from, to := c.indptr[i], c.indptr[i+1]
+		if from > to {
+			return ErrStructure
+		}
+		for k := from; k < to; k++ {
+			if c.ind[k] < 0 || c.ind[k] >= c.minor ||
+				(k > from && c.ind[k] <= c.ind[k-1]) {
+				return ErrStructure
+			}
+		}
+	}
+	return nil
+}
+
+func (c *compressed) nnz() int { return len(c.data) }
+
+// at returns element (i, j), with i along the major dimension.
+func (c *compressed) at(i, j int) float64 {
+	if i < 0 || i >= c.major || j < 0 || j >= c.minor {
+		panic(ErrIndexOutOfRange)
+	}
+	from, to := c.indptr[i], c.indptr[i+1]
+	k := from + sort.SearchInts(c.ind[from:to], j)
+	if k < to && c.ind[k] == j {
+		return c.data[k]
+	}
+	return 0
+}
+
+// vec returns major vector i as a view.
+func (c *compressed) vec(i int) *Float64Sparse {
+	if i < 0 || i >= c.major {
+		panic(ErrIndexOutOfRange)
+	}
+	from, to := c.indptr[i], c.indptr[i+1]
+	return &Float64Sparse{c.minor, c.ind[from:to:to], c.data[from:to:to]}
+}
+
+// transpose returns the storage of the same elements with the
+// dimensions swapped, e.g. a CSC of the matrix whose CSR is c.
+// Visiting the major vectors in order leaves the new minor
+// indices increasing.
+func (c *compressed) transpose() compressed {
+	t := compressed{
+		major:  c.minor,
+		minor:  c.major,
+		indptr: make([]int, c.minor+1),
+		ind:    make([]int, c.nnz()),
+		data:   make([]float64, c.nnz()),
+	}
+	for _, j := range c.ind {
+		t.indptr[j+1]++
+	}
+	for j := 0; j < t.major; j++ {
+		t.indptr[j+1] += t.indptr[j]
+	}
+	next := append([]int(nil), t.indptr[:t.major]...)
+	for i := 0; i < c.major; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			j := c.ind[k]
+			t.ind[next[j]] = i
+			t.data[next[j]] = c.data[k]
+			next[j]++
+		}
+	}
+	return t
+}
+
+// add_scaled returns the storage of c + s * b, which have the same
+// dimensions. Elements that cancel out are dropped.
+func (c *compressed) add_scaled(b *compressed, s float64) compressed {
+	out := compressed{
+		major:  c.major,
+		minor:  c.minor,
+		indptr: make([]int, c.major+1),
+		ind:    make([]int, 0, c.nnz()+b.nnz()),
+		data:   make([]float64, 0, c.nnz()+b.nnz()),
+	}
+	for i := 0; i < c.major; i++ {
+		p, pend := c.indptr[i], c.indptr[i+1]
+		q, qend := b.indptr[i], b.indptr[i+1]
+		for p < pend || q < qend {
+			var j int
+			var v float64
+			switch {
+			case q == qend || (p < pend && c.ind[p] < b.ind[q]):
+				j, v = c.ind[p], c.data[p]
+				p++
+			case p == pend || b.ind[q] < c.ind[p]:
+				j, v = b.ind[q], s*b.data[q]
+				q++
+			default:
+				j, v = c.ind[p], c.data[p]+s*b.data[q]
+				p++
+				q++
+			}
+			if v != 0 {
+				out.ind = append(out.ind, j)
+				out.data = append(out.data, v)
+			}
+		}
+		out.indptr[i+1] = len(out.ind)
+	}
+	return out
+}
+
+// full copies the elements into out, which has been zeroed,
+// transposed if trans is true.
+func (c *compressed) full(out *dense.Dense, trans bool) {
+	for i := 0; i < c.major; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			if trans {
+				out.Set(c.ind[k], i, c.data[k])
+			} else {
+				out.Set(i, c.ind[k], c.data[k])
+			}
+		}
+	}
+}
+
+// from_dense collects the nonzeros of m, transposed if trans is true.
+func from_dense(m dense.Matrix, trans bool) compressed {
+	r, cols := m.Dims()
+	at := m.At
+	if trans {
+		r, cols = cols, r
+		at = func(i, j int) float64 { return m.At(j, i) }
+	}
+	c := compressed{major: r, minor: cols, indptr: make([]int, r+1)}
+	for i := 0; i < r; i++ {
+		for j := 0; j < cols; j++ {
+			if v := at(i, j); v != 0 {
+				c.ind = append(c.ind, j)
+				c.data = append(c.data, v)
+			}
+		}
+		c.indptr[i+1] = len(c.ind)
+	}
+	return c
+}
+
+// use_out returns out zeroed if it has shape r by c,
+// a new matrix if out is nil, and an error otherwise.
+func use_out(op string, out *dense.Dense, r, c int) (*dense.Dense, error) {
+	if out == nil {
+		return dense.NewDense(r, c), nil
+	}
+	if m, n := out.Dims(); m != r || n != c {
+		return nil, &dense.ShapeError{Op: op, Err: dense.ErrOutShape,
+			Dims: [][2]int{{r, c}, {m, n}}}
+	}
+	out.Fill(0)
+	return out, nil
+}
+
+// shape_error returns the error for mismatched operands a and b.
+func shape_error(op string, e error, a, b dense.Matrix) error {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	return &dense.ShapeError{Op: op, Err: e, Dims: [][2]int{{ar, ac}, {br, bc}}}
+}
+
+func must_dense(m *dense.Dense, e error) *dense.Dense {
+	if e != nil {
+		panic(e)
+	}
+	return m
+}
+
+func must_slice(x []float64, e error) []float64 {
+	if e != nil {
+		panic(e)
+	}
+	return x
+}
+
+// mult_vec adds the product with x to y: y_i += sum_j c_ij x_j, i along
+// the major dimension, if scatter is false, or y_j += sum_i c_ij x_i,
+// if scatter is true.
+func (c *compressed) mult_vec(scatter bool, x, y []float64) {
+	for i := 0; i < c.major; i++ {
+		from, to := c.indptr[i], c.indptr[i+1]
+		if scatter {
+			xi := x[i]
+			for k := from; k < to; k++ {
+				y[c.ind[k]] += c.data[k] * xi
+			}
+		} else {
+			v := 0.0
+			for k := from; k < to; k++ {
+				v += c.data[k] * x[c.ind[k]]
+			}
+			y[i] += v
+		}
+	}
+}
+
+// mult_dense adds the product with b to out, in the same fashion
+// as mult_vec, with rows of b and out in place of elements.
+func (c *compressed) mult_dense(scatter bool, b, out *dense.Dense) {
+	for i := 0; i < c.major; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			src, dst := c.ind[k], i
+			if scatter {
+				src, dst = i, c.ind[k]
+			}
+			v, row := c.data[k], out.RowView(dst)
+			for j, x := range b.RowView(src) {
+				row[j] += v * x
+			}
+		}
+	}
+}
+
+// use_vec checks x against the r by c matrix m, and returns out zeroed,
+// or a new slice if out is nil.
+func use_vec(op string, m dense.Matrix, x, out []float64, r, c int) ([]float64, error) {
+	if len(x) != c {
+		return nil, &dense.ShapeError{Op: op, Err: dense.ErrInLength,
+			Dims: [][2]int{{r, c}, {len(x), 1}}}
+	}
+	if out == nil {
+		return make([]float64, r), nil
+	}
+	if len(out) != r {
+		return nil, dense.ErrOutLength
+	}
+	for i := range out {
+		out[i] = 0
+	}
+	return out, nil
+}
+
+// use_mult_out checks b against the r by c matrix m, and returns
+// b and the output for the product of m and b. b is copied
+// if it is out itself.
+func use_mult_out(op string, m dense.Matrix, b, out *dense.Dense, r, c int) (*dense.Dense, *dense.Dense, error) {
+	if b.Rows() != c {
+		return nil, nil, shape_error(op, dense.ErrShapes, m, b)
+	}
+	if out == b {
+		b = dense.Clone(b)
+	}
+	out, e := use_out(op, out, r, b.Cols())
+	return b, out, e
+}
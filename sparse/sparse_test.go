@@ -0,0 +1,51 @@
+package sparse
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/zpz/matrix.go/dense"
+	check "launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+// rand_sparse returns an r by c dense matrix of which about
+// a fraction p of the elements are nonzero.
+func rand_sparse(r, c int, p float64) *dense.Dense {
+	m := dense.NewDense(r, c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if rand.Float64() < p {
+				m.Set(i, j, rand.NormFloat64())
+			}
+		}
+	}
+	return m
+}
+
+func (s *S) TestCompressed(c *check.C) {
+	m := rand_sparse(7, 5, 0.3)
+	a := from_dense(m, false)
+	c.Check(a.check(), check.IsNil)
+
+	t := a.transpose()
+	c.Check(t.check(), check.IsNil)
+	c.Check(dense.Equal(&CSC{t}, m), check.Equals, true)
+	tt := t.transpose()
+	c.Check(tt, check.DeepEquals, a)
+
+	for _, bad := range []compressed{
+		{2, 2, []int{0, 1}, []int{0}, []float64{1}},
+		{2, 2, []int{0, 2, 1}, []int{0, 1}, []float64{1, 2}},
+		{2, 2, []int{0, 2, 2}, []int{1, 0}, []float64{1, 2}},
+		{2, 2, []int{0, 1, 2}, []int{0, 2}, []float64{1, 2}},
+		{2, 2, []int{0, 1, 2}, []int{0, 1}, []float64{1}},
+	} {
+		c.Check(bad.check(), check.Equals, ErrStructure, check.Commentf("%v", bad))
+	}
+}
@@ -0,0 +1,88 @@
+package sparse
+
+import (
+	"sort"
+
+	"github.com/zpz/matrix.go/dense"
+)
+
+// Float64Sparse is a sparse vector view, such as a row of a CSR
+// or a col of a CSC matrix; it plays the role that dense.Float64Stride
+// plays for a col of a Dense. Its nonzeros are at increasing
+// indices ind, with values data.
+type Float64Sparse struct {
+	n    int
+	ind  []int
+	data []float64
+}
+
+// Len returns the length of the vector, zeros included.
+func (me *Float64Sparse) Len() int {
+	return me.n
+}
+
+// NNZ returns the number of stored elements.
+func (me *Float64Sparse) NNZ() int {
+	return len(me.ind)
+}
+
+// Indices returns the indices of the stored elements.
+// The slice is a view; do not change it.
+func (me *Float64Sparse) Indices() []int {
+	return me.ind
+}
+
+// Values returns the stored elements as a view,
+// through which they may be changed.
+func (me *Float64Sparse) Values() []float64 {
+	return me.data
+}
+
+func (me *Float64Sparse) Get(i int) float64 {
+	if i < 0 || i >= me.n {
+		panic(ErrIndexOutOfRange)
+	}
+	k := sort.SearchInts(me.ind, i)
+	if k < len(me.ind) && me.ind[k] == i {
+		return me.data[k]
+	}
+	return 0
+}
+
+// CopyToSlice copies the vector, zeros included, into out.
+// If out is nil, a new slice is allocated;
+// otherwise out must have length Len().
+func (me *Float64Sparse) CopyToSlice(out []float64) []float64 {
+	if out == nil {
+		out = make([]float64, me.n)
+	} else if len(out) != me.n {
+		panic(dense.ErrOutLength)
+	}
+	for i := range out {
+		out[i] = 0
+	}
+	for k, i := range me.ind {
+		out[i] = me.data[k]
+	}
+	return out
+}
+
+// Dot returns the dot product of the vector with the dense x.
+func (me *Float64Sparse) Dot(x []float64) float64 {
+	if len(x) != me.n {
+		panic(dense.ErrLengths)
+	}
+	v := 0.0
+	for k, i := range me.ind {
+		v += me.data[k] * x[i]
+	}
+	return v
+}
+
+func (me *Float64Sparse) Sum() float64 {
+	v := 0.0
+	for _, x := range me.data {
+		v += x
+	}
+	return v
+}